@@ -4,5 +4,6 @@ go 1.19
 
 require (
 	github.com/google/go-cmp v0.5.9
+	github.com/lib/pq v1.9.0
 	github.com/mattn/go-sqlite3 v1.14.16
 )
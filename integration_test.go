@@ -53,6 +53,10 @@ func TestIntegration(t *testing.T) {
 				args:   []string{"add-schedule", "-name", "default"},
 				status: 0,
 			},
+			{
+				args:   []string{"add-person", "-name=alice"},
+				status: 0,
+			},
 			{
 				args:   []string{"add-interval", "-schedule", "default", "-who", "alice", "-start", "2022-10-31T15:40:00-04:00", "-for", "24h"},
 				status: 0,
@@ -63,6 +67,10 @@ func TestIntegration(t *testing.T) {
 				args:   []string{"add-schedule", "-name", "default"},
 				status: 0,
 			},
+			{
+				args:   []string{"add-person", "-name=alice"},
+				status: 0,
+			},
 			{
 				args:   []string{"add-interval", "-schedule", "default", "-who", "alice", "-start", "2022-10-31T15:40:00-04:00", "-for", "24h"},
 				status: 0,
@@ -77,6 +85,14 @@ func TestIntegration(t *testing.T) {
 				args:   []string{"add-schedule", "-name", "default"},
 				status: 0,
 			},
+			{
+				args:   []string{"add-person", "-name=alice"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=bob"},
+				status: 0,
+			},
 			{
 				args:   []string{"add-interval", "-schedule", "default", "-who", "alice", "-start", "2022-11-01T00:00:00Z", "-for", "24h"},
 				status: 0,
@@ -95,6 +111,14 @@ func TestIntegration(t *testing.T) {
 				args:   []string{"add-schedule", "-name=default"},
 				status: 0,
 			},
+			{
+				args:   []string{"add-person", "-name=alice"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=bob"},
+				status: 0,
+			},
 			{
 				args:   []string{"edit", "-schedule=default", "-add=bob=2023-01-01T00:00:00Z", "-add=alice=2023-01-01T00:00:00Z"},
 				status: 0,
@@ -111,8 +135,20 @@ func TestIntegration(t *testing.T) {
 				args:   []string{"show-schedule", "-schedule", "default", "-start=2023-01-01T00:00:00Z", "-end=2023-02-01T00:00:00Z"},
 				status: 0,
 			},
+			{
+				args:   []string{"report", "-schedule", "default", "-start=2023-01-01T00:00:00Z", "-end=2023-02-01T00:00:00Z", "-cost=saturday=2,sunday=2"},
+				status: 0,
+			},
 		},
 		{
+			{
+				args:   []string{"add-person", "-name=alice"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=bob"},
+				status: 0,
+			},
 			{
 				args:  []string{"apply", "-schedule=testschedule"},
 				stdin: fixture(t, "fixtures/apply.in.csv"),
@@ -126,10 +162,621 @@ func TestIntegration(t *testing.T) {
 			args:   []string{"help"},
 			status: 0,
 		}},
+		{
+			{
+				args:   []string{"add-schedule", "-name=default"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=alice"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=bob"},
+				status: 0,
+			},
+			{
+				args:   []string{"import-holidays", "-schedule=default", "-tz=America/New_York"},
+				stdin:  fixture(t, "fixtures/holidays.csv"),
+				status: 0,
+			},
+			{
+				args:   []string{"add-interval", "-schedule=default", "-who=alice", "-start=2023-12-22T12:00:00-05:00", "-end=2023-12-29T12:00:00-05:00"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-interval", "-schedule=default", "-who=bob", "-start=2023-12-29T12:00:00-05:00", "-end=2024-01-05T12:00:00-05:00"},
+				status: 0,
+			},
+			{
+				args:   []string{"show-schedule", "-schedule=default", "-start=2023-12-15T00:00:00Z", "-end=2024-01-15T00:00:00Z"},
+				status: 0,
+			},
+		},
+		{
+			{
+				args:   []string{"add-schedule", "-name=default"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=alice"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=bob"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=cindy"},
+				status: 0,
+			},
+			{
+				args:   []string{"edit", "-schedule=default", "-add=alice=2023-01-01T00:00:00Z", "-add=bob=2023-01-01T00:00:00Z"},
+				status: 0,
+			},
+			{
+				args:   []string{"generate", "-schedule=default", "-start=2023-01-02T00:00:00Z", "-end=2023-01-23T00:00:00Z", "-style=Weekly", "-params=day=Monday"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-interval", "-schedule=default", "-who=cindy", "-start=2023-01-10T00:00:00Z", "-for=24h"},
+				status: 0,
+			},
+			{
+				args:   []string{"generate", "-schedule=default", "-start=2023-01-02T00:00:00Z", "-end=2023-01-23T00:00:00Z", "-style=EveryNDays", "-params=days=3", "-dry-run"},
+				status: 0,
+			},
+			{
+				args:   []string{"generate", "-schedule=default", "-start=2023-01-02T00:00:00Z", "-end=2023-01-23T00:00:00Z", "-style=EveryNDays", "-params=days=3", "-dry-run", "-format=csv"},
+				status: 0,
+			},
+			{
+				args:   []string{"show-schedule", "-schedule=default", "-start=2023-01-02T00:00:00Z", "-end=2023-01-23T00:00:00Z"},
+				status: 0,
+			},
+		},
+		{
+			{
+				args:   []string{"add-schedule", "-name=default"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=alice"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=bob"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=cindy"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-layer", "-schedule=default", "-name=secondary"},
+				status: 0,
+			},
+			{
+				args:   []string{"edit", "-schedule=default", "-add=alice=2023-01-01T00:00:00Z", "-add=bob=2023-01-01T00:00:00Z", "-add=cindy=2023-01-01T00:00:00Z"},
+				status: 0,
+			},
+			{
+				args:   []string{"generate", "-schedule=default", "-start=2023-01-02T00:00:00Z", "-end=2023-01-23T00:00:00Z", "-style=Weekly", "-params=day=Monday"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-interval", "-schedule=default", "-who=alice", "-layer=secondary", "-start=2023-01-03T00:00:00Z", "-for=24h"},
+				status: 17,
+			},
+			{
+				args:   []string{"add-interval", "-schedule=default", "-who=cindy", "-layer=secondary", "-start=2023-01-03T00:00:00Z", "-for=24h"},
+				status: 0,
+			},
+			{
+				args:   []string{"show-schedule", "-schedule=default", "-start=2023-01-02T00:00:00Z", "-end=2023-01-23T00:00:00Z"},
+				status: 0,
+			},
+			{
+				args:  []string{"apply", "-schedule=default"},
+				stdin: fixture(t, "fixtures/apply.layers.csv"),
+			},
+		},
+		{
+			{
+				args:   []string{"add-schedule", "-name=default"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=alice"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=bob"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=dan"},
+				status: 0,
+			},
+			{
+				args:   []string{"edit", "-schedule=default", "-add=alice=2023-01-01T00:00:00Z", "-add=bob=2023-01-01T00:00:00Z", "-add=dan=2023-01-01T00:00:00Z", "-shadow=dan=2023-01-09T00:00:00Z"},
+				status: 0,
+			},
+			{
+				args:   []string{"generate", "-schedule=default", "-start=2023-01-02T00:00:00Z", "-end=2023-01-23T00:00:00Z", "-style=Weekly", "-params=day=Monday"},
+				status: 0,
+			},
+			{
+				args:   []string{"show-schedule", "-schedule=default", "-start=2023-01-02T00:00:00Z", "-end=2023-01-23T00:00:00Z"},
+				status: 0,
+			},
+			{
+				args:  []string{"apply", "-schedule=default"},
+				stdin: fixture(t, "fixtures/apply.shadows.csv"),
+			},
+		},
+		{
+			{
+				args:   []string{"add-schedule", "-name=default"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=alice"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=bob"},
+				status: 0,
+			},
+			{
+				args:   []string{"edit", "-schedule=default", "-add=alice=2023-01-01T00:00:00Z", "-add=bob=2023-01-01T00:00:00Z"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-interval", "-schedule=default", "-who=alice", "-kind=PREFERENCE", "-start=2023-01-02T00:00:00Z", "-for=168h"},
+				status: 1,
+			},
+			{
+				args:   []string{"add-interval", "-schedule=default", "-who=alice", "-kind=PREFERENCE", "-weight=-1", "-start=2023-01-02T00:00:00Z", "-for=168h"},
+				status: 0,
+			},
+			{
+				args:   []string{"generate", "-schedule=default", "-start=2023-01-02T00:00:00Z", "-end=2023-01-16T00:00:00Z", "-style=Weekly", "-params=day=Monday"},
+				status: 0,
+			},
+			{
+				args:   []string{"show-schedule", "-schedule=default", "-start=2023-01-02T00:00:00Z", "-end=2023-01-16T00:00:00Z"},
+				status: 0,
+			},
+			{
+				args:   []string{"generate", "-schedule=default", "-start=2023-01-02T00:00:00Z", "-end=2023-01-16T00:00:00Z", "-style=Weekly", "-params=day=Monday", "-seed=42", "-dry-run"},
+				status: 0,
+			},
+		},
+		{
+			{
+				args:   []string{"add-schedule", "-name=default"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=alice"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=bob"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=cindy"},
+				status: 0,
+			},
+			{
+				args:   []string{"edit", "-schedule=default", "-add=alice=2023-01-01T00:00:00Z", "-add=bob=2023-01-01T00:00:00Z", "-add=cindy=2023-01-01T00:00:00Z", "-tag=cindy=senior"},
+				status: 0,
+			},
+			{
+				args:   []string{"generate", "-schedule=default", "-start=2023-01-02T00:00:00Z", "-end=2023-01-23T00:00:00Z", "-style=Weekly", "-params=day=Monday"},
+				status: 0,
+			},
+			{
+				args:   []string{"set-requirement", "-schedule=default", "-tag=senior", "-at-least=2", "-at-most=1"},
+				status: 1,
+			},
+			{
+				args:   []string{"set-requirement", "-schedule=default", "-tag=senior", "-at-least=1"},
+				status: 0,
+			},
+			{
+				args:   []string{"validate", "-schedule=default", "-start=2023-01-02T00:00:00Z", "-end=2023-01-23T00:00:00Z"},
+				status: 1,
+			},
+			{
+				args:   []string{"generate", "-schedule=default", "-start=2023-01-02T00:00:00Z", "-end=2023-01-23T00:00:00Z", "-style=Weekly", "-params=day=Monday"},
+				status: 0,
+			},
+			{
+				args:   []string{"validate", "-schedule=default", "-start=2023-01-02T00:00:00Z", "-end=2023-01-23T00:00:00Z"},
+				status: 0,
+			},
+			{
+				args:   []string{"show-schedule", "-schedule=default", "-start=2023-01-02T00:00:00Z", "-end=2023-01-23T00:00:00Z"},
+				status: 0,
+			},
+		},
+		{
+			{
+				args:   []string{"add-schedule", "-name=default"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=alice", "-display-name=Alice Anders", "-email=alice@example.com", "-tz=Europe/Berlin", "-identity=opsgenie=aanders"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=alice"},
+				status: 1,
+			},
+			{
+				args:   []string{"add-person", "-name=bob", "-tz=Nowhere/Special"},
+				status: 1,
+			},
+			{
+				args:   []string{"add-person", "-name=bob"},
+				status: 0,
+			},
+			{
+				args:   []string{"update-person", "-name=bob", "-email=bob@example.com", "-identity=opsgenie=bobby"},
+				status: 0,
+			},
+			{
+				args:   []string{"update-person", "-name=alice", "-identity=opsgenie="},
+				status: 0,
+			},
+			{
+				args:   []string{"update-person", "-name=cindy", "-email=cindy@example.com"},
+				status: 1,
+			},
+			{
+				args:   []string{"edit", "-schedule=default", "-add=alcie=2023-01-01T00:00:00Z"},
+				status: 1,
+			},
+			{
+				args:   []string{"list-people"},
+				status: 0,
+			},
+		},
+		{
+			{
+				args:   []string{"add-schedule", "-name=default"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=alice"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=bob"},
+				status: 0,
+			},
+			{
+				args:   []string{"edit", "-schedule=default", "-add=alice=2023-01-01T00:00:00Z", "-add=bob=2023-01-01T00:00:00Z"},
+				status: 0,
+			},
+			{
+				args:   []string{"generate", "-schedule=default", "-start=2023-01-02T00:00:00Z", "-end=2023-01-16T00:00:00Z", "-style=Weekly", "-params=day=Monday"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-interval", "-schedule=default", "-who=bob", "-start=2023-01-03T00:00:00Z", "-for=24h", "-author=carol", "-reason=swapped a day with alice"},
+				status: 0,
+			},
+			{
+				args:   []string{"show-schedule", "-schedule=default", "-start=2023-01-02T00:00:00Z", "-end=2023-01-16T00:00:00Z", "-verbose"},
+				status: 0,
+			},
+		},
+		{
+			{
+				args:   []string{"add-schedule", "-name=default"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=alice"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=bob"},
+				status: 0,
+			},
+			{
+				args:   []string{"edit", "-schedule=default", "-add=alice=2023-01-01T00:00:00Z", "-add=bob=2023-01-01T00:00:00Z"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-interval", "-schedule=default", "-who=alice", "-kind=EXCLUSION", "-start=2023-01-03T00:00:00Z", "-for=24h"},
+				status: 0,
+			},
+			{
+				args:   []string{"generate", "-schedule=default", "-start=2023-01-02T00:00:00Z", "-end=2023-01-16T00:00:00Z", "-style=Weekly", "-params=day=Monday"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-interval", "-schedule=default", "-who=alice", "-start=2023-01-10T00:00:00Z", "-for=24h"},
+				status: 0,
+			},
+			{
+				args:   []string{"show-schedule", "-schedule=default", "-start=2023-01-02T00:00:00Z", "-end=2023-01-16T00:00:00Z", "-verbose"},
+				status: 0,
+			},
+			{
+				args:   []string{"remove-interval", "-schedule=default", "-who=alice", "-kind=EXCLUSION", "-start=2023-01-03T00:00:00Z", "-for=24h"},
+				status: 0,
+			},
+			{
+				args:   []string{"remove-interval", "-id=5"},
+				status: 0,
+			},
+			{
+				args:   []string{"remove-interval", "-id=5"},
+				status: 1,
+			},
+			{
+				args:   []string{"remove-interval", "-id=99"},
+				status: 1,
+			},
+			{
+				args:   []string{"remove-interval", "-schedule=default", "-who=bob", "-start=2023-01-03T00:00:00Z", "-for=1h"},
+				status: 1,
+			},
+			{
+				args:   []string{"show-schedule", "-schedule=default", "-start=2023-01-02T00:00:00Z", "-end=2023-01-16T00:00:00Z", "-verbose"},
+				status: 0,
+			},
+		},
+		{
+			{
+				args:   []string{"add-schedule", "-name=default"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-schedule", "-name=other"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=alice"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=bob"},
+				status: 0,
+			},
+			{
+				args:   []string{"edit", "-schedule=default", "-add=alice=2023-01-01T00:00:00Z", "-add=bob=2023-01-01T00:00:00Z"},
+				status: 0,
+			},
+			{
+				args:   []string{"edit", "-schedule=other", "-add=alice=2023-01-01T00:00:00Z", "-add=bob=2023-01-01T00:00:00Z"},
+				status: 0,
+			},
+			{
+				args:   []string{"generate", "-schedule=other", "-start=2023-01-02T00:00:00Z", "-end=2023-01-16T00:00:00Z", "-style=Weekly", "-params=day=Monday"},
+				status: 0,
+			},
+			{
+				args:   []string{"show-schedule", "-schedule=other", "-start=2023-01-02T00:00:00Z", "-end=2023-01-16T00:00:00Z"},
+				status: 0,
+			},
+			{
+				args:   []string{"history"},
+				status: 0,
+			},
+			{
+				args:   []string{"history", "-id=6"},
+				status: 0,
+			},
+			{
+				args:   []string{"undo", "7"},
+				status: 0,
+			},
+			{
+				args:   []string{"show-schedule", "-schedule=other", "-start=2023-01-02T00:00:00Z", "-end=2023-01-16T00:00:00Z"},
+				status: 0,
+			},
+			{
+				args:   []string{"undo", "7"},
+				status: 1,
+			},
+			{
+				args:   []string{"undo", "8"},
+				status: 0,
+			},
+			{
+				args:   []string{"show-schedule", "-schedule=other", "-start=2023-01-02T00:00:00Z", "-end=2023-01-16T00:00:00Z"},
+				status: 0,
+			},
+			{
+				args:   []string{"remove-interval", "-id=1"},
+				status: 0,
+			},
+			{
+				args:   []string{"undo", "9"},
+				status: 17,
+			},
+			{
+				args:   []string{"undo", "99"},
+				status: 1,
+			},
+			{
+				args:   []string{"undo"},
+				status: 1,
+			},
+			{
+				args:   []string{"history"},
+				status: 0,
+			},
+		},
+		{
+			{
+				args:   []string{"migrate"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-schedule", "-name=default"},
+				status: 0,
+			},
+		},
+		{
+			{
+				args:   []string{"add-schedule", "-name=default"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=alice"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=bob"},
+				status: 0,
+			},
+			{
+				args:   []string{"edit", "-schedule=default", "-add=alice=2023-01-01T00:00:00Z", "-add=bob=2023-01-01T00:00:00Z"},
+				status: 0,
+			},
+			{
+				args:   []string{"generate", "-schedule=default", "-start=2023-01-02T00:00:00Z", "-end=2023-01-23T00:00:00Z"},
+				status: 1,
+			},
+			{
+				args:   []string{"schedule", "set", "-name=default", "-tz=Europe/Berlin", "-description=Platform on call", "-style=Weekly", "-params=day=Monday,at=09:00", "-remote=opsgenie=platform_schedule", "-remote=stderr=platform"},
+				status: 0,
+			},
+			{
+				args:   []string{"schedule", "set", "-name=default", "-params=day=Tuesday"},
+				status: 0,
+			},
+			{
+				args:   []string{"schedule", "set", "-name=default", "-tz=Nowhere/Special"},
+				status: 1,
+			},
+			{
+				args:   []string{"schedule", "set", "-name=default", "-style=Unknown"},
+				status: 1,
+			},
+			{
+				args:   []string{"schedule", "show", "-name=unknown"},
+				status: 1,
+			},
+			{
+				args:   []string{"schedule", "-name=default"},
+				status: 1,
+			},
+			{
+				args:   []string{"schedule", "show", "-name=default"},
+				status: 0,
+			},
+			{
+				args:   []string{"generate", "-schedule=default", "-start=2023-01-02T00:00:00Z", "-end=2023-01-23T00:00:00Z"},
+				status: 0,
+			},
+			{
+				args:   []string{"show-schedule", "-schedule=default", "-start=2023-01-02T00:00:00Z", "-end=2023-01-23T00:00:00Z"},
+				status: 0,
+			},
+			{
+				args:   []string{"show-schedule", "-schedule=default", "-start=2023-01-02T00:00:00Z", "-end=2023-01-23T00:00:00Z", "-tz=UTC"},
+				status: 0,
+			},
+			{
+				args:  []string{"apply", "-schedule=default"},
+				stdin: strings.NewReader("start_at,end_before,person\n2023-01-03T08:00:00Z,2023-01-10T08:00:00Z,alice\n"),
+			},
+			{
+				args:   []string{"schedule", "set", "-name=default", "-remote=opsgenie=", "-description="},
+				status: 0,
+			},
+			{
+				args:   []string{"schedule", "show", "-name=default"},
+				status: 0,
+			},
+		},
+		{
+			{
+				args:   []string{"add-schedule", "-name=default"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-schedule", "-name=old"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=alice"},
+				status: 0,
+			},
+			{
+				args:   []string{"add-person", "-name=bob"},
+				status: 0,
+			},
+			{
+				args:   []string{"edit", "-schedule=default", "-add=alice=2023-01-01T00:00:00Z", "-add=bob=2023-01-01T00:00:00Z"},
+				status: 0,
+			},
+			{
+				args:   []string{"schedule", "set", "-name=default", "-description=Platform on call", "-style=Weekly", "-params=day=Monday", "-remote=opsgenie=platform_schedule"},
+				status: 0,
+			},
+			{
+				args:   []string{"generate", "-schedule=default", "-start=2023-01-02T00:00:00Z", "-end=2023-01-23T00:00:00Z"},
+				status: 0,
+			},
+			{
+				args:   []string{"rename-schedule", "-from=default", "-to=old"},
+				status: 1,
+			},
+			{
+				args:   []string{"rename-schedule", "-from=default", "-to=platform"},
+				status: 0,
+			},
+			{
+				args:   []string{"clone-schedule", "-from=platform", "-to=reorg", "-since=2023-01-09T00:00:00Z"},
+				status: 0,
+			},
+			{
+				args:   []string{"show-schedule", "-schedule=reorg", "-start=2023-01-02T00:00:00Z", "-end=2023-01-23T00:00:00Z"},
+				status: 0,
+			},
+			{
+				args:   []string{"schedule", "show", "-name=reorg"},
+				status: 0,
+			},
+			{
+				args:   []string{"delete-schedule", "-name=old"},
+				status: 1,
+			},
+			{
+				args:   []string{"archive-schedule", "-name=old"},
+				status: 0,
+			},
+			{
+				args:   []string{"list-schedules"},
+				status: 0,
+			},
+			{
+				args:   []string{"list-schedules", "-all"},
+				status: 0,
+			},
+			{
+				args:   []string{"delete-schedule", "-name=old"},
+				status: 0,
+			},
+			{
+				args:   []string{"list-schedules", "-all"},
+				status: 0,
+			},
+		},
 	} {
 		t.Run("", func(t *testing.T) {
 			dbname := filepath.Join(t.TempDir(), "db.sqlite3")
-			db, err := save.Open(dbname, nil)
+			db, err := save.OpenUnchecked(dbname, nil)
 			assert.Nil(t, err)
 			defer db.Close()
 			_, err = db.ExecContext(ctx, save.Schema)
@@ -142,6 +789,7 @@ func TestIntegration(t *testing.T) {
 					cmd := exec.CommandContext(ctx, prog, ttt.args...)
 					cmd.Env = append(os.Environ(),
 						"DETERMINISTIC=1",
+						"PAGER_AUTHOR=tester",
 						"DB="+dbname,
 					)
 					fmt.Fprintf(io.MultiWriter(stdout, stderr), "# %s\n", strings.Join(cmd.Args[1:], " "))
@@ -8,17 +8,22 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"net/url"
 	"os"
 	"os/exec"
 	"os/signal"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/jreut/pager/v2/pkg/cli"
 	"github.com/jreut/pager/v2/pkg/cmd"
+	"github.com/jreut/pager/v2/pkg/cost"
 	"github.com/jreut/pager/v2/pkg/global"
+	"github.com/jreut/pager/v2/pkg/holiday"
 	"github.com/jreut/pager/v2/pkg/interval"
 	"github.com/jreut/pager/v2/pkg/og"
 	"github.com/jreut/pager/v2/pkg/save"
@@ -65,7 +70,16 @@ func main() {
 		log.Fatalf("unhandled command %q: choose one of %s", os.Args[1], names)
 	}
 
-	db, err := save.Open(dbpath, nil)
+	open := save.Open
+	if command.db != nil {
+		open = save.OpenUnchecked
+	}
+	readonly := command.readonly != nil && command.readonly(os.Args[2:])
+	var o url.Values
+	if readonly {
+		o = save.ReadOnly(dbpath)
+	}
+	db, err := open(dbpath, o)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -73,11 +87,18 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
 	defer cancel()
 
-	err = save.WithTx(db, func(tx *sql.Tx) error {
-		return command.f(ctx, os.Args[2:], opts{
-			q: save.New(tx),
+	if command.db != nil {
+		err = command.db(ctx, os.Args[2:], db)
+	} else {
+		err = save.WithTx(db, func(tx *sql.Tx) error {
+			q := save.New(tx)
+			run := func() error { return command.f(ctx, os.Args[2:], opts{q: q}) }
+			if readonly {
+				return run()
+			}
+			return cmd.Record(ctx, q, os.Args[1], os.Args[2:], authorof(""), run)
 		})
-	})
+	}
 	if err != nil {
 		log.Println(err)
 		switch {
@@ -95,8 +116,17 @@ type opts struct{ q *save.Queries }
 type command struct {
 	help string
 	f    func(context.Context, []string, opts) error
+	// db runs instead of f for commands that manage the database itself.
+	// It gets the database whatever version its schema is at, outside of any transaction or changeset.
+	db func(context.Context, []string, *sql.DB) error
+	// readonly tells whether f only reads the database when given these args.
+	// Then it doesn't take the write lock or record a changeset.
+	readonly func(args []string) bool
 }
 
+// always is the readonly of commands that never write.
+func always([]string) bool { return true }
+
 func help(names []string) error {
 	// Iterate over this slice of names instead of cmds directly.
 	// This lets us pass a sorted list during tests.
@@ -144,21 +174,217 @@ var cmds = map[string]command{
 			return opts.q.AddSchedule(ctx, *name)
 		},
 	},
+	"list-schedules": {
+		help: "Print the schedules as a CSV, with how many people and intervals each has. Archived schedules only show with -all.",
+		f: func(ctx context.Context, args []string, opts opts) error {
+			all := flag.Bool("all", false, "include archived schedules")
+			if err := flag.CommandLine.Parse(args); err != nil {
+				return err
+			}
+			schedules, err := cmd.ListSchedules(ctx, opts.q, *all)
+			if err != nil {
+				return err
+			}
+			return cmd.WriteSchedules(os.Stdout, schedules)
+		},
+		readonly: always,
+	},
+	"rename-schedule": {
+		help: "Rename a schedule, along with everything in it.",
+		f: func(ctx context.Context, args []string, opts opts) error {
+			from := flag.String("from", "", "")
+			to := flag.String("to", "", "")
+			if err := flag.CommandLine.Parse(args); err != nil {
+				return err
+			}
+			return cmd.RenameSchedule(ctx, opts.q, *from, *to)
+		},
+	},
+	"archive-schedule": {
+		help: "Hide a schedule from `list-schedules`, or bring it back with -restore. Only archived schedules can be deleted.",
+		f: func(ctx context.Context, args []string, opts opts) error {
+			name := flag.String("name", "", "")
+			restore := flag.Bool("restore", false, "bring the schedule back instead")
+			if err := flag.CommandLine.Parse(args); err != nil {
+				return err
+			}
+			return cmd.ArchiveSchedule(ctx, opts.q, *name, *restore)
+		},
+	},
+	"delete-schedule": {
+		help: "Delete an archived schedule and everything in it. `undo` brings it back.",
+		f: func(ctx context.Context, args []string, opts opts) error {
+			name := flag.String("name", "", "")
+			if err := flag.CommandLine.Parse(args); err != nil {
+				return err
+			}
+			return cmd.DeleteSchedule(ctx, opts.q, *name)
+		},
+	},
+	"clone-schedule": {
+		help: "Copy a schedule into a new one to try changes out on, like a reorg. The clone gets the schedule's people, tags, layers, holidays, constraints, requirements and settings, and the intervals that end after -since, but not its remotes.",
+		f: func(ctx context.Context, args []string, opts opts) error {
+			from := flag.String("from", "", "")
+			to := flag.String("to", "", "")
+			since := flag.String("since", "", "copy intervals that end after this time (default now)")
+			if err := flag.CommandLine.Parse(args); err != nil {
+				return err
+			}
+			t := global.Now()
+			if *since != "" {
+				var err error
+				t, err = time.Parse(time.RFC3339, *since)
+				if err != nil {
+					return fmt.Errorf("parsing -since: %w", err)
+				}
+			}
+			return cmd.CloneSchedule(ctx, opts.q, *from, *to, t)
+		},
+	},
+	"schedule": {
+		help: "Show or set a schedule's settings, like `schedule show -name=default` or `schedule set -name=default -tz=Europe/Berlin`. Commands that take the schedule fall back on its timezone, its style and parameters for `generate`, and its remote at each destination for `apply`. Settings without a flag stay as they are, and an empty -remote removes the schedule's remote at that destination.",
+		f: func(ctx context.Context, args []string, opts opts) error {
+			var sub string
+			if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+				sub, args = args[0], args[1:]
+			}
+			var given cmd.Settings
+			given.Remotes = make(map[string]string)
+			flag.StringVar(&given.Schedule, "name", "", "")
+			flag.StringVar(&given.Timezone, "tz", "", "location to show times in, like Europe/Berlin")
+			flag.StringVar(&given.Description, "description", "", "")
+			style := flag.String("style", "", "style for generate, like Weekly")
+			params := flag.String("params", "", "comma-separated key=value parameters for -style")
+			flag.Var(identitiesflag(given.Remotes), "remote", "destination=remote, like opsgenie=<schedule ID or name>")
+			if err := flag.CommandLine.Parse(args); err != nil {
+				return err
+			}
+			s, err := cmd.GetSettings(ctx, opts.q, given.Schedule)
+			if err != nil {
+				return err
+			}
+			switch sub {
+			case "show":
+				return cmd.WriteSettings(os.Stdout, s)
+			case "set":
+			default:
+				return fmt.Errorf("unhandled subcommand %q: choose show or set", sub)
+			}
+			if isset(flag.CommandLine, "style") {
+				s.Style, s.StyleParams = *style, nil
+			}
+			if isset(flag.CommandLine, "params") {
+				s.StyleParams, err = cmd.ParseStyleParams(*params)
+				if err != nil {
+					return err
+				}
+			}
+			flag.Visit(func(f *flag.Flag) {
+				switch f.Name {
+				case "tz":
+					s.Timezone = given.Timezone
+				case "description":
+					s.Description = given.Description
+				}
+			})
+			s.Remotes = given.Remotes
+			return cmd.SetSettings(ctx, opts.q, s)
+		},
+		readonly: func(args []string) bool {
+			return len(args) > 0 && args[0] == "show"
+		},
+	},
+	"add-person": {
+		help: "Add someone who can join schedules. Schedules, shifts and tags only accept people added first.",
+		f: func(ctx context.Context, args []string, opts opts) error {
+			var p cmd.Person
+			personflags(&p)
+			if err := flag.CommandLine.Parse(args); err != nil {
+				return err
+			}
+			return cmd.AddPerson(ctx, opts.q, p)
+		},
+	},
+	"update-person": {
+		help: "Change the details of someone already added. Details without a flag stay as they are, and an empty -identity removes the person's identity at that destination.",
+		f: func(ctx context.Context, args []string, opts opts) error {
+			var given cmd.Person
+			personflags(&given)
+			if err := flag.CommandLine.Parse(args); err != nil {
+				return err
+			}
+			p, err := cmd.GetPerson(ctx, opts.q, given.Name)
+			if err != nil {
+				return err
+			}
+			p.Identities = given.Identities
+			flag.Visit(func(f *flag.Flag) {
+				switch f.Name {
+				case "display-name":
+					p.DisplayName = given.DisplayName
+				case "email":
+					p.Email = given.Email
+				case "tz":
+					p.Timezone = given.Timezone
+				}
+			})
+			return cmd.UpdatePerson(ctx, opts.q, p)
+		},
+	},
+	"list-people": {
+		help: "Print everyone who was added as a CSV.",
+		f: func(ctx context.Context, args []string, opts opts) error {
+			if err := flag.CommandLine.Parse(args); err != nil {
+				return err
+			}
+			people, err := cmd.People(ctx, opts.q)
+			if err != nil {
+				return err
+			}
+			return cmd.WritePeople(os.Stdout, people)
+		},
+		readonly: always,
+	},
+	"add-layer": {
+		help: "Add a layer to a schedule, such as a secondary on-call. Every schedule has a primary layer, and `generate` fills each layer with a different person.",
+		f: func(ctx context.Context, args []string, opts opts) error {
+			schedule := flag.String("schedule", "", "")
+			name := flag.String("name", "", "")
+			if err := flag.CommandLine.Parse(args); err != nil {
+				return err
+			}
+			return cmd.AddLayer(ctx, opts.q, *schedule, *name)
+		},
+	},
 	"add-interval": {
-		help: "Add an ad hoc shift or an exclusion to the schedule. This is useful for things like covering someone for an hour.",
+		help: "Add an ad hoc shift or an exclusion to the schedule. This is useful for things like covering someone for an hour. A preference softly asks `generate` for shifts, or to avoid them.",
 		f: func(ctx context.Context, args []string, opts opts) error {
 			times := cli.TimeFlags()
 			who := flag.String("who", "", "who")
-			kind := flag.String("kind", save.IntervalKindShift, fmt.Sprintf("one of %s", []string{save.IntervalKindShift, save.IntervalKindExclusion}))
+			kind := flag.String("kind", save.IntervalKindShift, fmt.Sprintf("one of %s", []string{save.IntervalKindShift, save.IntervalKindExclusion, save.IntervalKindPreference}))
 			schedule := flag.String("schedule", "", "")
+			layer := flag.String("layer", save.LayerPrimary, "layer for a shift, or the primary layer if empty")
+			weight := flag.Float64("weight", 0, "for a preference, how much to ask for shifts if positive or to avoid them if negative; -1 counts like having already worked that time")
+			priority := flag.Int64("priority", 0, "where it overlaps other intervals, higher priority wins over newer")
+			reason := flag.String("reason", "", "why, for the record")
+			author := authorflag()
 			if err := flag.CommandLine.Parse(args); err != nil {
 				return err
 			}
 			if *who == "" {
 				return fmt.Errorf("provide -who")
 			}
-			if !(*kind == save.IntervalKindExclusion || *kind == save.IntervalKindShift) {
-				return fmt.Errorf("provide -kind=%s or -kind=%s", save.IntervalKindShift, save.IntervalKindExclusion)
+			switch *kind {
+			case save.IntervalKindShift, save.IntervalKindExclusion:
+				if *weight != 0 {
+					return fmt.Errorf("provide -weight only with -kind=%s", save.IntervalKindPreference)
+				}
+			case save.IntervalKindPreference:
+				if *weight == 0 {
+					return fmt.Errorf("provide nonzero -weight with -kind=%s", save.IntervalKindPreference)
+				}
+			default:
+				return fmt.Errorf("provide -kind=%s, -kind=%s or -kind=%s", save.IntervalKindShift, save.IntervalKindExclusion, save.IntervalKindPreference)
 			}
 			start, end, err := times.Times()
 			if err != nil {
@@ -170,14 +396,183 @@ var cmds = map[string]command{
 				StartAt:   start,
 				EndBefore: end,
 				Kind:      *kind,
+				Layer:     *layer,
+				Weight:    *weight,
+				Source:    save.IntervalSourceManual,
+				Author:    authorof(*author),
+				Reason:    *reason,
+				Priority:  *priority,
 			}, false)
 		},
 	},
+	"history": {
+		help: "Print a CSV of every command that changed anything, oldest first, with how many rows each one touched. With -id, print the rows that one command touched instead, as they were before and after.",
+		f: func(ctx context.Context, args []string, opts opts) error {
+			id := flag.Int64("id", 0, "the changeset to show the rows of")
+			if err := flag.CommandLine.Parse(args); err != nil {
+				return err
+			}
+			if *id != 0 {
+				changes, err := opts.q.ListChanges(ctx, *id)
+				if err != nil {
+					return err
+				}
+				return cmd.WriteChanges(os.Stdout, changes)
+			}
+			history, err := cmd.History(ctx, opts.q)
+			if err != nil {
+				return err
+			}
+			return cmd.WriteHistory(os.Stdout, history)
+		},
+		readonly: always,
+	},
+	"undo": {
+		help: "Undo everything one command changed, given the ID of its changeset from `history`, like `undo 12`. Undoing an undo redoes it. Undo refuses if a later command changed the same rows, until that is undone too.",
+		f: func(ctx context.Context, args []string, opts opts) error {
+			if err := flag.CommandLine.Parse(args); err != nil {
+				return err
+			}
+			if flag.NArg() != 1 {
+				return fmt.Errorf("provide the ID of one changeset")
+			}
+			id, err := strconv.ParseInt(flag.Arg(0), 10, 64)
+			if err != nil {
+				return fmt.Errorf("parsing changeset ID: %w", err)
+			}
+			return cmd.Undo(ctx, opts.q, id)
+		},
+	},
+	"migrate": {
+		help: "Create the database, or bring its schema up to date, in one transaction. Every other command refuses to run until the database is up to date, and nothing runs against a database that a newer version migrated.",
+		db: func(ctx context.Context, args []string, db *sql.DB) error {
+			if err := flag.CommandLine.Parse(args); err != nil {
+				return err
+			}
+			from, to, err := save.Migrate(ctx, db)
+			if err != nil {
+				return err
+			}
+			if from == to {
+				log.Printf("already at schema version %d", to)
+			} else {
+				log.Printf("migrated from schema version %d to %d", from, to)
+			}
+			return nil
+		},
+	},
+	"remove-interval": {
+		help: "Remove an interval, picked by -id or by matching all of its details exactly. The interval stops counting, but stays on record. Shifts that `generate` reassigned around a removed exclusion stay as they are until generated again.",
+		f: func(ctx context.Context, args []string, opts opts) error {
+			id := flag.Int64("id", 0, "the ID of the interval, as shown by show-schedule -verbose")
+			times := cli.TimeFlags()
+			who := flag.String("who", "", "who")
+			kind := flag.String("kind", save.IntervalKindShift, fmt.Sprintf("one of %s", []string{save.IntervalKindShift, save.IntervalKindExclusion, save.IntervalKindPreference, save.IntervalKindShadow}))
+			schedule := flag.String("schedule", "", "")
+			layer := flag.String("layer", save.LayerPrimary, "layer for a shift, or the primary layer if empty")
+			author := authorflag()
+			if err := flag.CommandLine.Parse(args); err != nil {
+				return err
+			}
+			arg := cmd.RemoveIntervalParams{ID: *id, Author: authorof(*author)}
+			if *id == 0 {
+				if *who == "" {
+					return fmt.Errorf("provide -id, or -who and the other details to match")
+				}
+				start, end, err := times.Times()
+				if err != nil {
+					return err
+				}
+				arg.Match = save.FindIntervalsParams{
+					Schedule:  *schedule,
+					Person:    *who,
+					Kind:      *kind,
+					Layer:     *layer,
+					StartAt:   start,
+					EndBefore: end,
+				}
+			}
+			removed, stale, err := cmd.RemoveInterval(ctx, opts.q, arg)
+			if err != nil {
+				return err
+			}
+			log.Printf("removed %s", removed)
+			for _, i := range stale {
+				log.Printf("warning: %s may cover for the removed exclusion and stays as it is: generate again to reassign it", i)
+			}
+			return nil
+		},
+	},
 	"show-schedule": {
-		help: "Print the schedule for the given time interval as a CSV.",
+		help: "Print the schedule for the given time interval as a CSV, with times in the schedule's timezone if it has one. If the schedule has more layers than the primary, a column names the layer of each shift, and each layer follows the one before. If anyone shadows during the time interval, their shadow shifts follow, and a column tells shifts from shadows. If the schedule has holidays, a column names the holidays during each shift. With -verbose, more columns tell where each shift came from.",
+		f: func(ctx context.Context, args []string, opts opts) error {
+			schedule := flag.String("schedule", "", "")
+			times := cli.TimeFlags()
+			verbose := flag.Bool("verbose", false, "add columns for where each shift came from, who added it, why, and when")
+			tz := flag.String("tz", "", "print times in this location (default the schedule's timezone, or else as they were added)")
+			if err := flag.CommandLine.Parse(args); err != nil {
+				return err
+			}
+			start, end, err := times.Times()
+			if err != nil {
+				return err
+			}
+			settings, err := cmd.GetSettings(ctx, opts.q, *schedule)
+			if err != nil {
+				return err
+			}
+			if *tz != "" {
+				settings.Timezone = *tz
+			}
+			out, err := cmd.ShowLayers(ctx, opts.q, *schedule, start, end)
+			if err != nil {
+				return err
+			}
+			layers, err := cmd.Layers(ctx, opts.q, *schedule)
+			if err != nil {
+				return err
+			}
+			holidays, err := opts.q.ListHolidays(ctx, *schedule)
+			if err != nil {
+				return err
+			}
+			shadows, err := cmd.ShowShadows(ctx, opts.q, *schedule, start, end)
+			if err != nil {
+				return err
+			}
+			var columns []interval.Column
+			if len(layers) > 1 {
+				columns = append(columns, cmd.LayerColumn)
+			}
+			if len(shadows) > 0 {
+				out = append(out, shadows...)
+				columns = append(columns, cmd.KindColumn)
+			}
+			if len(holidays) > 0 {
+				columns = append(columns, cmd.HolidayColumn(holidays))
+			}
+			if *verbose {
+				columns = append(columns, cmd.ProvenanceColumns...)
+			}
+			if settings.Timezone != "" {
+				loc, err := settings.Location()
+				if err != nil {
+					return err
+				}
+				for i := range out {
+					out[i].StartAt, out[i].EndBefore = out[i].StartAt.In(loc), out[i].EndBefore.In(loc)
+				}
+			}
+			return interval.WriteCSV(os.Stdout, out, columns...)
+		},
+		readonly: always,
+	},
+	"report": {
+		help: "Print how much time each person spent on call in the given time interval as a CSV, both raw and weighted by -cost.",
 		f: func(ctx context.Context, args []string, opts opts) error {
 			schedule := flag.String("schedule", "", "")
 			times := cli.TimeFlags()
+			costs := flag.String("cost", "", costUsage)
 			if err := flag.CommandLine.Parse(args); err != nil {
 				return err
 			}
@@ -185,20 +580,28 @@ var cmds = map[string]command{
 			if err != nil {
 				return err
 			}
-			out, err := cmd.ShowSchedule(ctx, opts.q, *schedule, start, end)
+			m, err := cost.Parse(*costs)
 			if err != nil {
 				return err
 			}
-			return interval.WriteCSV(os.Stdout, out)
+			out, err := cmd.Report(ctx, opts.q, *schedule, start, end, m)
+			if err != nil {
+				return err
+			}
+			return cmd.WriteReportCSV(os.Stdout, out)
 		},
+		readonly: always,
 	},
 	"edit": {
-		help: "Add or remove people from a schedule. Adding a person makes them eligible for shifts generated by `generate`, and removing them does the opposite.",
+		help: "Add or remove people from a schedule. Adding a person makes them eligible for shifts generated by `generate`, and removing them does the opposite. Shadowing people pair with whoever is on call without being paged, until they join the real rotation. Tags label people, like with their region for the FollowTheSun style.",
 		f: func(ctx context.Context, args []string, opts opts) error {
 			schedule := flag.String("schedule", "", "")
 			var actions []cmd.Action
 			flag.Var(actionsflag{save.EventKindAdd, &actions}, "add", "")
 			flag.Var(actionsflag{save.EventKindRemove, &actions}, "remove", "")
+			flag.Var(actionsflag{save.EventKindShadow, &actions}, "shadow", "person=time: until then, shadow whoever is on call instead of taking shifts")
+			flag.Var(tagsflag{cmd.ActionTag, &actions}, "tag", "person=tag")
+			flag.Var(tagsflag{cmd.ActionUntag, &actions}, "untag", "person=tag")
 			if err := flag.CommandLine.Parse(args); err != nil {
 				return err
 			}
@@ -209,7 +612,16 @@ var cmds = map[string]command{
 		f: func(ctx context.Context, args []string, opts opts) error {
 			schedule := flag.String("schedule", "", "")
 			times := cli.TimeFlags()
-			style := flag.String("style", "", "")
+			style := flag.String("style", "", stylesUsage()+"\n(default the schedule's style)")
+			params := flag.String("params", "", "comma-separated key=value parameters for -style, like day=Tuesday,at=10:00,tz=Europe/Berlin (default the schedule's parameters for its style)")
+			lookback := flag.Duration("lookback", 90*24*time.Hour, "count existing shifts from this long before -start toward fairness")
+			costs := flag.String("cost", "", costUsage)
+			holidayHandoffs := flag.Bool("holiday-handoffs", false, "also hand off at the start and end of each holiday")
+			appendShifts := flag.Bool("append", false, "keep shifts generated earlier in the range instead of replacing them")
+			dryRun := flag.Bool("dry-run", false, "print what would change without saving it")
+			format := flag.String("format", "text", "how to print the -dry-run changes: text or csv")
+			seed := flag.Int64("seed", 0, "shuffle people whose tallies tie with this seed, so the same seed and inputs give the same shifts; 0 picks a seed at random and logs it, or breaks ties in order of name when DETERMINISTIC=1")
+			author := authorflag()
 			if err := flag.CommandLine.Parse(args); err != nil {
 				return err
 			}
@@ -217,14 +629,128 @@ var cmds = map[string]command{
 			if err != nil {
 				return err
 			}
-			return cmd.Generate(ctx, opts.q, *schedule, *style, start, end)
+			var p cmd.StyleParams
+			if isset(flag.CommandLine, "params") {
+				p, err = cmd.ParseStyleParams(*params)
+				if err != nil {
+					return err
+				}
+			}
+			m, err := cost.Parse(*costs)
+			if err != nil {
+				return err
+			}
+			var write func(io.Writer, []cmd.Change) error
+			switch *format {
+			case "text":
+				write = cmd.WriteDiff
+			case "csv":
+				write = cmd.WriteDiffCSV
+			default:
+				return fmt.Errorf("unhandled -format %q: choose text or csv", *format)
+			}
+			if *seed == 0 && !global.Deterministic() {
+				*seed = time.Now().UnixNano()
+			}
+			if *seed != 0 {
+				log.Printf("generating with -seed=%d", *seed)
+			}
+			var before []save.Interval
+			if *dryRun {
+				before, err = cmd.ShowLayers(ctx, opts.q, *schedule, start, end)
+				if err != nil {
+					return err
+				}
+			}
+			if err := cmd.Generate(ctx, opts.q, cmd.GenerateParams{
+				Schedule:        *schedule,
+				Style:           *style,
+				StyleParams:     p,
+				StartAt:         start,
+				EndBefore:       end,
+				Lookback:        *lookback,
+				Cost:            m,
+				HolidayHandoffs: *holidayHandoffs,
+				Append:          *appendShifts,
+				Seed:            *seed,
+				Author:          authorof(*author),
+			}); err != nil {
+				return err
+			}
+			if !*dryRun {
+				return nil
+			}
+			after, err := cmd.ShowLayers(ctx, opts.q, *schedule, start, end)
+			if err != nil {
+				return err
+			}
+			if err := write(os.Stdout, cmd.Diff(before, after)); err != nil {
+				return err
+			}
+			return save.ErrRollback
+		},
+	},
+	"set-constraints": {
+		help: "Set the hard limits that `generate` never breaks for a schedule. Zero means no limit.",
+		f: func(ctx context.Context, args []string, opts opts) error {
+			schedule := flag.String("schedule", "", "")
+			var c cmd.Constraints
+			flag.DurationVar(&c.MinRest, "min-rest", 0, "shortest time off between two shifts for the same person")
+			flag.IntVar(&c.MaxConsecutive, "max-consecutive", 0, "most shifts in a row for the same person")
+			flag.DurationVar(&c.MaxPerWindow, "max-per-30-days", 0, "most time on call for the same person during any 30 days")
+			if err := flag.CommandLine.Parse(args); err != nil {
+				return err
+			}
+			return cmd.SetConstraints(ctx, opts.q, *schedule, c)
 		},
 	},
+	"set-requirement": {
+		help: "Require shifts in a schedule to have at least or at most so many people on call with a tag, counting every layer. `generate` meets the requirements, and `validate` reports where the schedule breaks them. Setting no limits removes the requirement.",
+		f: func(ctx context.Context, args []string, opts opts) error {
+			schedule := flag.String("schedule", "", "")
+			var r cmd.Requirement
+			flag.StringVar(&r.Tag, "tag", "", "")
+			flag.IntVar(&r.AtLeast, "at-least", 0, "fewest people with the tag on call at once")
+			flag.IntVar(&r.AtMost, "at-most", -1, "most people with the tag on call at once, or -1 for no limit")
+			if err := flag.CommandLine.Parse(args); err != nil {
+				return err
+			}
+			return cmd.SetRequirement(ctx, opts.q, *schedule, r)
+		},
+	},
+	"validate": {
+		help: "Print each time in the given time interval when the people on call break one of the schedule's requirements, and fail if there are any.",
+		f: func(ctx context.Context, args []string, opts opts) error {
+			schedule := flag.String("schedule", "", "")
+			times := cli.TimeFlags()
+			if err := flag.CommandLine.Parse(args); err != nil {
+				return err
+			}
+			start, end, err := times.Times()
+			if err != nil {
+				return err
+			}
+			violations, err := cmd.Validate(ctx, opts.q, *schedule, start, end)
+			if err != nil {
+				return err
+			}
+			if err := cmd.WriteViolations(os.Stdout, violations); err != nil {
+				return err
+			}
+			if len(violations) > 0 {
+				return fmt.Errorf("the schedule breaks its requirements %d times", len(violations))
+			}
+			return nil
+		},
+		readonly: always,
+	},
 	"import": {
 		help: "Overwrite the schedule with the given CSV of shifts.",
 		f: func(ctx context.Context, args []string, opts opts) error {
 			f := flag.String("file", "-", "csv file containing intervals, or stdin if '-'")
 			schedule := flag.String("schedule", "", "")
+			reason := flag.String("reason", "", "why, for the record")
+			author := authorflag()
 			if err := flag.CommandLine.Parse(args); err != nil {
 				return err
 			}
@@ -245,20 +771,63 @@ var cmds = map[string]command{
 				return err
 			}
 			for i := range is {
-				if err := cmd.AddInterval(ctx, opts.q, save.AddIntervalParams(is[i]), true); err != nil {
+				is[i].Source, is[i].Author, is[i].Reason = save.IntervalSourceImport, authorof(*author), *reason
+				if err := cmd.AddInterval(ctx, opts.q, is[i].Params(), true); err != nil {
 					return fmt.Errorf("AddInterval[%d]: %w\n%s", i, err, is[i])
 				}
 			}
 			return nil
 		},
 	},
+	"import-holidays": {
+		help: "Add holidays to the schedule from a CSV of date,name records or from an iCalendar file.",
+		f: func(ctx context.Context, args []string, opts opts) error {
+			f := flag.String("file", "-", "file containing holidays, or stdin if '-'")
+			format := flag.String("format", "csv", "one of [csv ics]")
+			tz := flag.String("tz", "UTC", "holidays start and end at midnight in this location")
+			schedule := flag.String("schedule", "", "")
+			if err := flag.CommandLine.Parse(args); err != nil {
+				return err
+			}
+			if *schedule == "" {
+				return fmt.Errorf("provide nonempty -schedule")
+			}
+			loc, err := time.LoadLocation(*tz)
+			if err != nil {
+				return err
+			}
+			r := os.Stdin
+			if *f != "-" {
+				var err error
+				r, err = os.Open(*f)
+				if err != nil {
+					return err
+				}
+				defer r.Close()
+			}
+			var hs []save.Holiday
+			switch *format {
+			case "csv":
+				hs, err = holiday.ReadCSV(r, *schedule, loc)
+			case "ics":
+				hs, err = holiday.ReadICS(r, *schedule, loc)
+			default:
+				return fmt.Errorf("unhandled format %q", *format)
+			}
+			if err != nil {
+				return err
+			}
+			return cmd.ImportHolidays(ctx, opts.q, hs)
+		},
+	},
 	"apply": {
-		help: "Write the schedule to the pager provider.",
+		help: "Write the schedule to the pager provider, to the schedule's remote there if it has one.",
 		f: func(ctx context.Context, args []string, opts opts) error {
 			f := flag.String("file", "-", "csv file containing intervals, or stdin if '-'")
 			d := flag.String("dst", "stderr", "write to this external destination")
 			schedule := flag.String("schedule", "", "")
 			debug := flag.Bool("debug", false, "")
+			shadows := flag.String("shadows", "", "rotation at the destination for shadow shifts, or skip them if empty")
 			if err := flag.CommandLine.Parse(args); err != nil {
 				return err
 			}
@@ -277,17 +846,89 @@ var cmds = map[string]command{
 			var dst cmd.Destination
 			switch *d {
 			case "opsgenie":
-				dst = og.NewHTTPClient(og.DomainDefault, "TODO-KEY", *debug)
+				usernames, err := cmd.Identities(ctx, opts.q, *d)
+				if err != nil {
+					return err
+				}
+				dst = og.NewHTTPClient(og.DomainDefault, "TODO-KEY", *debug).ShadowRotation(*shadows).Usernames(usernames)
 			case "stderr":
 				dst = cmd.FakeDestination{Writer: os.Stderr}
 			default:
 				return fmt.Errorf("unhandled destination %q", *d)
 			}
-			return cmd.Apply(ctx, r, dst, *schedule)
+			remote, err := cmd.RemoteSchedule(ctx, opts.q, *schedule, *d)
+			if err != nil {
+				return err
+			}
+			return cmd.Apply(ctx, opts.q, r, dst, *schedule, remote)
 		},
+		readonly: always,
 	},
 }
 
+const costUsage = "comma-separated weights for time on call, like tz=America/New_York,saturday=2,sunday=2,22-06=1.5,2023-12-25=3"
+
+func stylesUsage() string {
+	var b strings.Builder
+	b.WriteString("one of")
+	for _, k := range cmd.StyleNames() {
+		fmt.Fprintf(&b, "\n%s: %s", k, cmd.Styles[k].Help)
+	}
+	return b.String()
+}
+
+// isset tells whether the flag was set on the command line, even to its default.
+func isset(fs *flag.FlagSet, name string) bool {
+	var ok bool
+	fs.Visit(func(f *flag.Flag) {
+		ok = ok || f.Name == name
+	})
+	return ok
+}
+
+// authorflag names who makes a change, for the record.
+// Pass its value to authorof.
+func authorflag() *string {
+	return flag.String("author", "", "who makes this change, for the record (default $PAGER_AUTHOR, or else $USER)")
+}
+
+func authorof(flagged string) string {
+	if flagged != "" {
+		return flagged
+	}
+	if v := os.Getenv("PAGER_AUTHOR"); v != "" {
+		return v
+	}
+	return os.Getenv("USER")
+}
+
+func personflags(p *cmd.Person) {
+	p.Identities = make(map[string]string)
+	flag.StringVar(&p.Name, "name", "", "who schedules, shifts and tags refer to")
+	flag.StringVar(&p.DisplayName, "display-name", "", "")
+	flag.StringVar(&p.Email, "email", "", "")
+	flag.StringVar(&p.Timezone, "tz", "", "location, like America/New_York")
+	flag.Var(identitiesflag(p.Identities), "identity", "destination=identity, like opsgenie=alice@example.com")
+}
+
+type identitiesflag map[string]string
+
+func (f identitiesflag) Set(v string) error {
+	before, after, ok := strings.Cut(v, "=")
+	if !ok {
+		return fmt.Errorf("cannot parse %v: does not contain %q", v, "=")
+	}
+	if before == "" {
+		return fmt.Errorf("cannot parse %v: empty destination", v)
+	}
+	f[before] = after
+	return nil
+}
+
+func (f identitiesflag) String() string {
+	return fmt.Sprint(map[string]string(f))
+}
+
 type actionsflag struct {
 	kind string
 	val  *[]cmd.Action
@@ -313,3 +954,25 @@ func (f actionsflag) Set(v string) error {
 func (f actionsflag) String() string {
 	return fmt.Sprintf("%s: %s", f.kind, f.val)
 }
+
+type tagsflag struct {
+	kind string
+	val  *[]cmd.Action
+}
+
+func (f tagsflag) Set(v string) error {
+	before, after, ok := strings.Cut(v, "=")
+	if !ok {
+		return fmt.Errorf("cannot parse %v: does not contain %q", v, "=")
+	}
+	*f.val = append(*f.val, cmd.Action{
+		Kind: f.kind,
+		Who:  before,
+		Tag:  after,
+	})
+	return nil
+}
+
+func (f tagsflag) String() string {
+	return fmt.Sprintf("%s: %s", f.kind, f.val)
+}
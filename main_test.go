@@ -38,3 +38,21 @@ func TestActionsFlag(t *testing.T) {
 		})
 	}
 }
+
+func TestTagsFlag(t *testing.T) {
+	var val []cmd.Action
+	f := tagsflag{cmd.ActionTag, &val}
+	assert.Nil(t, f.Set("alice=apac"))
+	assert.Error(t, "does not contain", f.Set("alice"))
+	assert.Cmp(t, []cmd.Action{{Kind: cmd.ActionTag, Who: "alice", Tag: "apac"}}, val)
+}
+
+func TestIdentitiesFlag(t *testing.T) {
+	val := make(map[string]string)
+	f := identitiesflag(val)
+	assert.Nil(t, f.Set("opsgenie=alice@example.com"))
+	assert.Nil(t, f.Set("pagerduty="))
+	assert.Error(t, "does not contain", f.Set("opsgenie"))
+	assert.Error(t, "empty destination", f.Set("=alice"))
+	assert.Cmp(t, map[string]string{"opsgenie": "alice@example.com", "pagerduty": ""}, val)
+}
@@ -4,36 +4,93 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/jreut/pager/v2/pkg/global"
 	"github.com/jreut/pager/v2/pkg/interval"
 	"github.com/jreut/pager/v2/pkg/save"
 )
 
+// AddInterval adds the interval unless it conflicts with another.
+// Intervals without a creation time are created now.
 func AddInterval(ctx context.Context, q *save.Queries, arg save.AddIntervalParams, ignoreconflict bool) error {
+	if err := known(ctx, q, arg.Person); err != nil {
+		return err
+	}
+	if arg.CreatedAt.IsZero() {
+		arg.CreatedAt = global.Now()
+	}
 	if !ignoreconflict {
-		var conflict string
 		switch arg.Kind {
 		case save.IntervalKindExclusion:
-			conflict = save.IntervalKindShift
-		case save.IntervalKindShift:
-			conflict = save.IntervalKindExclusion
+			layers, err := Layers(ctx, q, arg.Schedule)
+			if err != nil {
+				return err
+			}
+			if err := shiftconflict(ctx, q, arg.Interval(), layers); err != nil {
+				return err
+			}
+		case save.IntervalKindShift, save.IntervalKindShadow:
+			existing, err := q.ListIntervals(ctx, save.ListIntervalsParams{
+				Schedule:  arg.Schedule,
+				Kind:      save.IntervalKindExclusion,
+				StartAt:   arg.StartAt,
+				EndBefore: arg.EndBefore,
+			})
+			if err != nil {
+				return err
+			}
+			if x, ok := interval.Conflict(existing, arg.Interval()); ok {
+				return fmt.Errorf("%w: cannot schedule %s over existing %s", ErrConflict, arg.Interval(), x)
+			}
+			if arg.Kind == save.IntervalKindShadow {
+				break
+			}
+			// Nobody can be on two layers at once.
+			layers, err := Layers(ctx, q, arg.Schedule)
+			if err != nil {
+				return err
+			}
+			var others []string
+			for _, l := range layers {
+				if l != arg.Layer {
+					others = append(others, l)
+				}
+			}
+			if err := shiftconflict(ctx, q, arg.Interval(), others); err != nil {
+				return err
+			}
+		case save.IntervalKindPreference:
+			// Preferences are soft, so nothing conflicts with them.
 		default:
 			return fmt.Errorf("unhandled kind %q", arg.Kind)
 		}
+	}
 
-		existing, err := q.ListIntervals(ctx, save.ListIntervalsParams{
-			Schedule:  arg.Schedule,
-			Kind:      conflict,
-			StartAt:   arg.StartAt,
-			EndBefore: arg.EndBefore,
-		})
-		if err != nil {
-			return err
-		}
+	return q.AddInterval(ctx, arg)
+}
 
-		if x, ok := interval.Conflict(existing, save.Interval(arg)); ok {
-			return fmt.Errorf("%w: cannot schedule %s over existing %s", ErrConflict, save.Interval(arg), x)
+// shiftconflict fails if y overlaps a shift of its person on any of the layers.
+// Each layer counts once it is flattened like [ShowLayer] does, so a shift that a later one overrides doesn't get in the way,
+// and a shift on one layer doesn't hide another layer's.
+func shiftconflict(ctx context.Context, q *save.Queries, y save.Interval, layers []string) error {
+	shifts, err := q.ListIntervals(ctx, save.ListIntervalsParams{
+		Schedule:  y.Schedule,
+		Kind:      save.IntervalKindShift,
+		StartAt:   y.StartAt,
+		EndBefore: y.EndBefore,
+	})
+	if err != nil {
+		return err
+	}
+	for _, l := range layers {
+		var xs []save.Interval
+		for _, s := range shifts {
+			if s.Layer == l {
+				xs = append(xs, s)
+			}
+		}
+		if x, ok := interval.Conflict(xs, y); ok {
+			return fmt.Errorf("%w: cannot schedule %s over existing %s", ErrConflict, y, x)
 		}
 	}
-
-	return q.AddInterval(ctx, arg)
+	return nil
 }
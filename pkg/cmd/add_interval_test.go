@@ -21,6 +21,7 @@ func TestAddInterval(t *testing.T) {
 		alice = "alice"
 		bob   = "bob"
 	)
+	const secondary = "secondary"
 
 	for _, tt := range []struct {
 		label     string
@@ -104,15 +105,102 @@ func TestAddInterval(t *testing.T) {
 			},
 			err: `cannot schedule EXCLUSION.*"alice".*over existing SHIFT`,
 		},
+		{
+			label: "preference over a shift is ok",
+			intervals: []save.Interval{
+				{Person: alice, StartAt: t0, EndBefore: t1, Kind: save.IntervalKindShift},
+			},
+			arg: save.AddIntervalParams{
+				Person:    alice,
+				StartAt:   t0,
+				EndBefore: t2,
+				Kind:      save.IntervalKindPreference,
+				Weight:    -1,
+			},
+			err: "",
+		},
+		{
+			label: "shift on another layer with different person is ok",
+			intervals: []save.Interval{
+				{Person: bob, StartAt: t0, EndBefore: t1, Kind: save.IntervalKindShift},
+			},
+			arg: save.AddIntervalParams{
+				Person:    alice,
+				StartAt:   t0,
+				EndBefore: t2,
+				Kind:      save.IntervalKindShift,
+				Layer:     secondary,
+			},
+			err: "",
+		},
+		{
+			label: "shift on another layer is not ok",
+			intervals: []save.Interval{
+				{Person: alice, StartAt: t0, EndBefore: t1, Kind: save.IntervalKindShift},
+			},
+			arg: save.AddIntervalParams{
+				Person:    alice,
+				StartAt:   t0,
+				EndBefore: t2,
+				Kind:      save.IntervalKindShift,
+				Layer:     secondary,
+			},
+			err: `cannot schedule SHIFT.*"alice".*layer "secondary".*over existing SHIFT`,
+		},
+		{
+			label: "shift on another layer that is overridden is ok",
+			intervals: []save.Interval{
+				{Person: alice, StartAt: t0, EndBefore: t2, Kind: save.IntervalKindShift},
+				{Person: bob, StartAt: t0, EndBefore: t1, Kind: save.IntervalKindShift},
+			},
+			arg: save.AddIntervalParams{
+				Person:    alice,
+				StartAt:   t0,
+				EndBefore: t1,
+				Kind:      save.IntervalKindShift,
+				Layer:     secondary,
+			},
+			err: "",
+		},
+		{
+			label: "exclusion over an overridden shift is ok",
+			intervals: []save.Interval{
+				{Person: alice, StartAt: t0, EndBefore: t2, Kind: save.IntervalKindShift},
+				{Person: bob, StartAt: t0, EndBefore: t1, Kind: save.IntervalKindShift},
+			},
+			arg: save.AddIntervalParams{
+				Person:    alice,
+				StartAt:   t0,
+				EndBefore: t1,
+				Kind:      save.IntervalKindExclusion,
+			},
+			err: "",
+		},
+		{
+			label: "exclusion over a shift under another layer's shift is not ok",
+			intervals: []save.Interval{
+				{Person: alice, StartAt: t0, EndBefore: t1, Kind: save.IntervalKindShift},
+				{Person: bob, StartAt: t0, EndBefore: t1, Kind: save.IntervalKindShift, Layer: secondary},
+			},
+			arg: save.AddIntervalParams{
+				Person:    alice,
+				StartAt:   t0,
+				EndBefore: t1,
+				Kind:      save.IntervalKindExclusion,
+			},
+			err: `cannot schedule EXCLUSION.*"alice".*over existing SHIFT`,
+		},
 	} {
 		t.Run(tt.label, func(t *testing.T) {
 			ctx := context.Background()
 			q := save.New(testdb(t, ctx))
+			addpeople(t, ctx, q, "alice", "bob")
 			const schedule = "default"
 			assert.Nil(t, q.AddSchedule(ctx, schedule))
+			assert.Nil(t, cmd.AddLayer(ctx, q, schedule, secondary))
 			for _, i := range tt.intervals {
 				i.Schedule = schedule
-				q.AddInterval(ctx, save.AddIntervalParams(i))
+				q.AddInterval(ctx, i.Params())
 			}
 			tt.arg.Schedule = schedule
 			err := cmd.AddInterval(ctx, q, tt.arg, false)
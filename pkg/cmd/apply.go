@@ -30,10 +30,22 @@ func (d FakeDestination) Apply(_ context.Context, schedule string, in []save.Int
 	return nil
 }
 
-func Apply(ctx context.Context, r io.Reader, dst Destination, schedule string) error {
+// Apply reads intervals as a CSV and writes them to the schedule called remote at the destination.
+// Everyone in them has to be added with [AddPerson] first.
+func Apply(ctx context.Context, q *save.Queries, r io.Reader, dst Destination, schedule, remote string) error {
 	in, err := interval.ReadCSV(r, schedule, save.IntervalKindShift)
 	if err != nil {
 		return err
 	}
-	return dst.Apply(ctx, schedule, in)
+	seen := make(map[string]bool)
+	for _, i := range in {
+		if seen[i.Person] {
+			continue
+		}
+		seen[i.Person] = true
+		if err := known(ctx, q, i.Person); err != nil {
+			return err
+		}
+	}
+	return dst.Apply(ctx, remote, in)
 }
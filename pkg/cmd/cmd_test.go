@@ -3,19 +3,21 @@ package cmd_test
 import (
 	"context"
 	"database/sql"
-	"net/url"
 	"testing"
 
 	"github.com/jreut/pager/v2/pkg/assert"
 	"github.com/jreut/pager/v2/pkg/save"
+	"github.com/jreut/pager/v2/pkg/save/savetest"
 )
 
 func testdb(t *testing.T, ctx context.Context) *sql.DB {
 	t.Helper()
-	db, err := save.Open(":memory:", url.Values{"cache": []string{"shared"}})
-	assert.Nil(t, err)
-	t.Cleanup(func() { db.Close() })
-	_, err = db.ExecContext(ctx, save.Schema)
-	assert.Nil(t, err)
-	return db
+	return savetest.DB(t, ctx)
+}
+
+func addpeople(t *testing.T, ctx context.Context, q *save.Queries, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		assert.Nil(t, q.AddPerson(ctx, save.AddPersonParams{Name: name}))
+	}
 }
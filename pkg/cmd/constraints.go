@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jreut/pager/v2/pkg/save"
+)
+
+// Window is how far back Constraints.MaxPerWindow looks.
+const Window = 30 * 24 * time.Hour
+
+// Constraints are hard limits that [Generate] never breaks.
+// Zero values mean no limit.
+type Constraints struct {
+	// MinRest is the shortest time someone gets off between two shifts.
+	// Shifts that follow each other directly count as consecutive instead.
+	MinRest time.Duration
+	// MaxConsecutive is the most shifts in a row that one person takes.
+	MaxConsecutive int
+	// MaxPerWindow is the most time someone spends on call during any [Window].
+	MaxPerWindow time.Duration
+}
+
+func (c Constraints) zero() bool {
+	return c == Constraints{}
+}
+
+func GetConstraints(ctx context.Context, q *save.Queries, schedule string) (Constraints, error) {
+	c, err := q.GetConstraints(ctx, schedule)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Constraints{}, nil
+	}
+	if err != nil {
+		return Constraints{}, err
+	}
+	return Constraints{
+		MinRest:        time.Duration(c.MinRestSeconds) * time.Second,
+		MaxConsecutive: int(c.MaxConsecutive),
+		MaxPerWindow:   time.Duration(c.MaxSecondsPer30Days) * time.Second,
+	}, nil
+}
+
+func SetConstraints(ctx context.Context, q *save.Queries, schedule string, c Constraints) error {
+	return q.SetConstraints(ctx, save.SetConstraintsParams{
+		Schedule:            schedule,
+		MinRestSeconds:      int64(c.MinRest / time.Second),
+		MaxConsecutive:      int64(c.MaxConsecutive),
+		MaxSecondsPer30Days: int64(c.MaxPerWindow / time.Second),
+	})
+}
+
+// tracker remembers who is on call when to check them against Constraints.
+type tracker struct {
+	Constraints
+	// shifts has all the time each person is on call, in any order.
+	shifts map[string][]save.Interval
+	// streak counts the slots in a row that each person worked, up to the end of their latest one in until.
+	streak map[string]int
+	until  map[string]time.Time
+}
+
+func newtracker(c Constraints) *tracker {
+	return &tracker{
+		Constraints: c,
+		shifts:      make(map[string][]save.Interval),
+		streak:      make(map[string]int),
+		until:       make(map[string]time.Time),
+	}
+}
+
+// add records that person is on call during [a, b).
+func (t *tracker) add(person string, a, b time.Time) {
+	t.shifts[person] = append(t.shifts[person], save.Interval{Person: person, StartAt: a, EndBefore: b})
+}
+
+// slot records that person works some of the rotation's slot [a, b), which carries on their streak if their last slot ended at a.
+// Add each person's slots once each, in chronological order.
+func (t *tracker) slot(person string, a, b time.Time) {
+	if t.until[person].Equal(a) {
+		t.streak[person]++
+	} else {
+		t.streak[person] = 1
+	}
+	t.until[person] = b
+}
+
+// check explains why person cannot be on call during [a, b) in the slot starting at slot, or returns nil if they can.
+func (t *tracker) check(person string, a, b, slot time.Time) error {
+	shifts := t.shifts[person]
+	if t.MinRest > 0 {
+		// Only the nearest shift on each side matters.
+		var before, after time.Time
+		for _, s := range shifts {
+			if !s.EndBefore.After(a) && s.EndBefore.After(before) {
+				before = s.EndBefore
+			}
+			if !s.StartAt.Before(b) && (after.IsZero() || s.StartAt.Before(after)) {
+				after = s.StartAt
+			}
+		}
+		if rest := a.Sub(before); !before.IsZero() && rest > 0 && rest < t.MinRest {
+			return fmt.Errorf("would rest only %s after their shift ending %s (min %s)", rest, before.Format(time.RFC3339), t.MinRest)
+		}
+		if rest := after.Sub(b); !after.IsZero() && rest > 0 && rest < t.MinRest {
+			return fmt.Errorf("would rest only %s before their shift starting %s (min %s)", rest, after.Format(time.RFC3339), t.MinRest)
+		}
+	}
+	if t.MaxConsecutive > 0 && t.until[person].Equal(slot) && t.streak[person] >= t.MaxConsecutive {
+		return fmt.Errorf("would take %d shifts in a row (max %d)", t.streak[person]+1, t.MaxConsecutive)
+	}
+	if t.MaxPerWindow > 0 {
+		// The most time on call in a window around [a, b) is in one that starts or ends with a shift.
+		ends := []time.Time{b, a.Add(Window)}
+		for _, s := range shifts {
+			ends = append(ends, s.EndBefore, s.StartAt.Add(Window))
+		}
+		for _, to := range ends {
+			from := to.Add(-Window)
+			if !from.Before(b) || !to.After(a) {
+				continue
+			}
+			total := overlap(a, b, from, to)
+			for _, s := range shifts {
+				total += overlap(s.StartAt, s.EndBefore, from, to)
+			}
+			if total > t.MaxPerWindow {
+				return fmt.Errorf("would be on call %s in the %s before %s (max %s)", total, Window, to.Format(time.RFC3339), t.MaxPerWindow)
+			}
+		}
+	}
+	return nil
+}
+
+// overlap is how much of [a, b) falls in [from, to).
+func overlap(a, b, from, to time.Time) time.Duration {
+	if a.Before(from) {
+		a = from
+	}
+	if b.After(to) {
+		b = to
+	}
+	if !a.Before(b) {
+		return 0
+	}
+	return b.Sub(a)
+}
+
+// blocked explains why nobody could take a shift.
+type blocked []string
+
+func (b *blocked) add(person string, err error) {
+	*b = append(*b, fmt.Sprintf("%s %v", person, err))
+}
+
+func (b blocked) String() string {
+	return strings.Join(b, "; ")
+}
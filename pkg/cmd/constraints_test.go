@@ -0,0 +1,261 @@
+package cmd_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jreut/pager/v2/pkg/assert"
+	"github.com/jreut/pager/v2/pkg/cmd"
+	"github.com/jreut/pager/v2/pkg/save"
+	"github.com/jreut/pager/v2/pkg/save/savetest"
+)
+
+func TestConstraints(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+
+	const schedule = "schedule"
+	assert.Nil(t, q.AddSchedule(ctx, schedule))
+
+	got, err := cmd.GetConstraints(ctx, q, schedule)
+	assert.Nil(t, err)
+	assert.Cmp(t, cmd.Constraints{}, got)
+
+	want := cmd.Constraints{MinRest: 12 * time.Hour, MaxConsecutive: 2, MaxPerWindow: 240 * time.Hour}
+	assert.Nil(t, cmd.SetConstraints(ctx, q, schedule, want))
+	got, err = cmd.GetConstraints(ctx, q, schedule)
+	assert.Nil(t, err)
+	assert.Cmp(t, want, got)
+
+	want = cmd.Constraints{MaxConsecutive: 3}
+	assert.Nil(t, cmd.SetConstraints(ctx, q, schedule, want))
+	got, err = cmd.GetConstraints(ctx, q, schedule)
+	assert.Nil(t, err)
+	assert.Cmp(t, want, got)
+
+	assert.Error(t, savetest.ErrCheck, cmd.SetConstraints(ctx, q, schedule, cmd.Constraints{MaxConsecutive: -1}))
+	assert.Error(t, savetest.ErrForeignKey, cmd.SetConstraints(ctx, q, "unknown", cmd.Constraints{}))
+}
+
+func TestGenerateConstraints(t *testing.T) {
+	start := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		label       string
+		people      []string
+		constraints cmd.Constraints
+		params      string
+		err         string
+	}{
+		{
+			// Bob is far behind alice, but never takes more than two days in a row.
+			label:       "MaxConsecutive",
+			people:      []string{"alice", "bob"},
+			constraints: cmd.Constraints{MaxConsecutive: 2},
+			params:      "days=1",
+		},
+		{
+			// Nobody comes back after only a day off, so people take two days in a row instead.
+			label:       "MinRest",
+			people:      []string{"alice", "bob", "cindy"},
+			constraints: cmd.Constraints{MinRest: 48 * time.Hour},
+			params:      "days=1",
+		},
+		{
+			label:       "MinRest_Impossible",
+			people:      []string{"alice", "bob"},
+			constraints: cmd.Constraints{MinRest: 36 * time.Hour, MaxConsecutive: 1},
+			params:      "days=1",
+			err:         `nobody can take shift from 2023-01-04 00:00:00 \+0000 UTC to 2023-01-05 00:00:00 \+0000 UTC without breaking a constraint: bob would rest only 24h0m0s after their shift ending 2023-01-03T00:00:00Z \(min 36h0m0s\); alice would take 2 shifts in a row \(max 1\)`,
+		},
+		{
+			label:       "MaxPerWindow_Impossible",
+			people:      []string{"alice", "bob"},
+			constraints: cmd.Constraints{MaxPerWindow: 200 * time.Hour},
+			params:      "days=7,from=2023-01-02",
+			err:         `bob would be on call 336h0m0s in the 720h0m0s before 2023-01-23T00:00:00Z \(max 200h0m0s\); alice would be on call 336h0m0s`,
+		},
+	} {
+		t.Run(tt.label, func(t *testing.T) {
+			ctx := context.Background()
+			q := save.New(testdb(t, ctx))
+			addpeople(t, ctx, q, tt.people...)
+
+			const schedule = "schedule"
+			assert.Nil(t, q.AddSchedule(ctx, schedule))
+			var actions []cmd.Action
+			for _, p := range tt.people {
+				actions = append(actions, cmd.Action{Who: p, At: start, Kind: save.EventKindAdd})
+			}
+			assert.Nil(t, cmd.EditSchedule(ctx, q, schedule, actions))
+			assert.Nil(t, cmd.SetConstraints(ctx, q, schedule, tt.constraints))
+			params, err := cmd.ParseStyleParams(tt.params)
+			assert.Nil(t, err)
+			// Seed alice with lots of time on call so that everyone else wants to catch up.
+			assert.Nil(t, q.AddInterval(ctx, save.AddIntervalParams{
+				Person:    "alice",
+				Schedule:  schedule,
+				StartAt:   start.AddDate(0, -3, 0),
+				EndBefore: start.AddDate(0, -2, 0),
+				Kind:      save.IntervalKindShift,
+			}))
+			end := start.AddDate(0, 0, 21)
+			err = cmd.Generate(ctx, q, cmd.GenerateParams{
+				Schedule:    schedule,
+				Style:       cmd.StyleEveryNDays,
+				StyleParams: params,
+				StartAt:     start,
+				EndBefore:   end,
+				Lookback:    90 * 24 * time.Hour,
+			})
+			if tt.err != "" {
+				assert.Error(t, tt.err, err)
+				return
+			}
+			assert.Nil(t, err)
+			got, err := cmd.ShowSchedule(ctx, q, schedule, start, end)
+			assert.Nil(t, err)
+			golden(t, got)
+		})
+	}
+}
+
+// TestGenerateConstraintsCover checks that covers keep to the constraints,
+// and count toward them instead of the person they cover.
+func TestGenerateConstraintsCover(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+	addpeople(t, ctx, q, "alice", "bob", "cindy")
+	start := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 21)
+
+	const schedule = "schedule"
+	assert.Nil(t, q.AddSchedule(ctx, schedule))
+	assert.Nil(t, cmd.EditSchedule(ctx, q, schedule, []cmd.Action{
+		{Who: "alice", At: start, Kind: save.EventKindAdd},
+		{Who: "bob", At: start, Kind: save.EventKindAdd},
+		{Who: "cindy", At: start, Kind: save.EventKindAdd},
+	}))
+	assert.Nil(t, cmd.SetConstraints(ctx, q, schedule, cmd.Constraints{MaxPerWindow: 200 * time.Hour}))
+	for _, i := range []save.AddIntervalParams{
+		// Seed alice with lots of time on call, long enough ago not to count toward the window.
+		{Person: "alice", StartAt: start.AddDate(0, -3, 0), EndBefore: start.AddDate(0, -2, 0), Kind: save.IntervalKindShift},
+		// Bob takes the first week, and cindy covers it.
+		{Person: "bob", StartAt: start, EndBefore: start.AddDate(0, 0, 7), Kind: save.IntervalKindExclusion},
+	} {
+		i.Schedule = schedule
+		assert.Nil(t, q.AddInterval(ctx, i))
+	}
+	assert.Nil(t, cmd.Generate(ctx, q, cmd.GenerateParams{
+		Schedule:    schedule,
+		Style:       cmd.StyleEveryNDays,
+		StyleParams: cmd.StyleParams{"days": "7", "from": "2023-01-02"},
+		StartAt:     start,
+		EndBefore:   end,
+		Lookback:    90 * 24 * time.Hour,
+	}))
+	got, err := cmd.ShowSchedule(ctx, q, schedule, start, end)
+	assert.Nil(t, err)
+	var lines []string
+	for _, i := range got {
+		lines = append(lines, i.StartAt.Format("2006-01-02")+" "+i.EndBefore.Format("2006-01-02")+" "+i.Person)
+	}
+	assert.Cmp(t, []string{
+		"2023-01-02 2023-01-09 cindy",
+		"2023-01-09 2023-01-16 bob",
+		// Bob's first week didn't count toward his window, but cindy's cover did.
+		"2023-01-16 2023-01-23 alice",
+	}, lines)
+}
+
+// TestGenerateConstraintsSplit checks that a cover for part of a shift doesn't break the streak of the person it covers.
+func TestGenerateConstraintsSplit(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+	addpeople(t, ctx, q, "alice", "bob")
+	start := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 4)
+
+	const schedule = "schedule"
+	assert.Nil(t, q.AddSchedule(ctx, schedule))
+	assert.Nil(t, cmd.EditSchedule(ctx, q, schedule, []cmd.Action{
+		{Who: "alice", At: start, Kind: save.EventKindAdd},
+		{Who: "bob", At: start, Kind: save.EventKindAdd},
+	}))
+	assert.Nil(t, cmd.SetConstraints(ctx, q, schedule, cmd.Constraints{MaxConsecutive: 2}))
+	for _, i := range []save.AddIntervalParams{
+		// Seed bob with lots of time on call so that alice takes every shift she can.
+		{Person: "bob", StartAt: start.AddDate(0, -2, 0), EndBefore: start.AddDate(0, -1, 0), Kind: save.IntervalKindShift},
+		// Bob covers the second half of alice's second day.
+		{Person: "alice", StartAt: start.Add(36 * time.Hour), EndBefore: start.Add(48 * time.Hour), Kind: save.IntervalKindExclusion},
+	} {
+		i.Schedule = schedule
+		assert.Nil(t, q.AddInterval(ctx, i))
+	}
+	assert.Nil(t, cmd.Generate(ctx, q, cmd.GenerateParams{
+		Schedule:    schedule,
+		Style:       cmd.StyleEveryNDays,
+		StyleParams: cmd.StyleParams{"days": "1", "from": "2023-01-02"},
+		StartAt:     start,
+		EndBefore:   end,
+		Lookback:    90 * 24 * time.Hour,
+	}))
+	assert.Cmp(t, []string{
+		"2023-01-02T00 2023-01-03T12 alice",
+		"2023-01-03T12 2023-01-05T00 bob",
+		// Alice worked some of each of the first two days, so she sits out the third.
+		"2023-01-05T00 2023-01-06T00 alice",
+	}, showlines(t, ctx, q, schedule, start, end))
+}
+
+// TestGenerateConstraintsKept checks that the shifts generating leaves in the range, like manual ones, count toward the constraints.
+func TestGenerateConstraintsKept(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+	addpeople(t, ctx, q, "alice", "bob", "cindy")
+	start := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 5)
+
+	const schedule = "schedule"
+	assert.Nil(t, q.AddSchedule(ctx, schedule))
+	assert.Nil(t, cmd.EditSchedule(ctx, q, schedule, []cmd.Action{
+		{Who: "alice", At: start, Kind: save.EventKindAdd},
+		{Who: "bob", At: start, Kind: save.EventKindAdd},
+		{Who: "cindy", At: start, Kind: save.EventKindAdd},
+	}))
+	assert.Nil(t, cmd.SetConstraints(ctx, q, schedule, cmd.Constraints{MinRest: 48 * time.Hour}))
+	// Bob takes the fourth day by hand, so he can't take the second.
+	assert.Nil(t, cmd.AddInterval(ctx, q, save.AddIntervalParams{
+		Person:    "bob",
+		Schedule:  schedule,
+		StartAt:   start.AddDate(0, 0, 3),
+		EndBefore: start.AddDate(0, 0, 4),
+		Kind:      save.IntervalKindShift,
+		Priority:  1,
+	}, false))
+	assert.Nil(t, cmd.Generate(ctx, q, cmd.GenerateParams{
+		Schedule:    schedule,
+		Style:       cmd.StyleEveryNDays,
+		StyleParams: cmd.StyleParams{"days": "1", "from": "2023-01-02"},
+		StartAt:     start,
+		EndBefore:   end,
+	}))
+	assert.Cmp(t, []string{
+		"2023-01-02T00 2023-01-03T00 alice",
+		"2023-01-03T00 2023-01-04T00 cindy",
+		// Bob's second day runs straight into the one he took by hand.
+		"2023-01-04T00 2023-01-07T00 bob",
+	}, showlines(t, ctx, q, schedule, start, end))
+}
+
+// showlines shows the schedule as one line per shift.
+func showlines(t *testing.T, ctx context.Context, q *save.Queries, schedule string, start, end time.Time) []string {
+	t.Helper()
+	got, err := cmd.ShowSchedule(ctx, q, schedule, start, end)
+	assert.Nil(t, err)
+	var lines []string
+	for _, i := range got {
+		lines = append(lines, i.StartAt.Format("2006-01-02T15")+" "+i.EndBefore.Format("2006-01-02T15")+" "+i.Person)
+	}
+	return lines
+}
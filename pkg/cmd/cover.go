@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/jreut/pager/v2/pkg/cost"
+	"github.com/jreut/pager/v2/pkg/save"
+)
+
+// rank sorts people so that whoever has the lowest tally comes first.
+//
+// With rng, ties fall in a shuffled order that only depends on rng.
+// Otherwise, ties fall in order of name.
+func rank(people []string, tally map[string]time.Duration, rng *rand.Rand) {
+	sort.Strings(people)
+	if rng != nil {
+		rng.Shuffle(len(people), func(i, j int) { people[i], people[j] = people[j], people[i] })
+	}
+	sort.SliceStable(people, func(i, j int) bool {
+		return tally[people[i]] < tally[people[j]]
+	})
+}
+
+// cover finds shifts to cover person's exclusions during [a, b).
+//
+// Each piece of an exclusion goes to whoever among people has the lowest tally, no exclusion of their own at that time,
+// room for it under track's constraints, and passes check.
+// When exclusions overlap, the cover splits between people.
+// The tally moves from person to whoever covers them.
+// Everyone adds the time they work to track, so person only gets the time nobody covers,
+// and everyone who works any of [a, b) carries on their streak of slots.
+func cover(person string, people []string, exclusions []save.Interval, a, b time.Time, tally map[string]time.Duration, model cost.Model, rng *rand.Rand, track *tracker, check func(string) error) ([]save.Interval, error) {
+	bounds := []time.Time{a, b}
+	for _, e := range exclusions {
+		for _, t := range []time.Time{e.StartAt, e.EndBefore} {
+			if t.After(a) && t.Before(b) {
+				bounds = append(bounds, t)
+			}
+		}
+	}
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i].Before(bounds[j]) })
+
+	// worked is the latest stretch that somebody works, which goes to track once somebody else takes over.
+	var worked save.Interval
+	workers := make(map[string]bool)
+	work := func(who string, x, y time.Time) {
+		workers[who] = true
+		if worked.Person == who && worked.EndBefore.Equal(x) {
+			worked.EndBefore = y
+			return
+		}
+		if worked.Person != "" {
+			track.add(worked.Person, worked.StartAt, worked.EndBefore)
+		}
+		worked = save.Interval{Person: who, StartAt: x, EndBefore: y}
+	}
+	var out []save.Interval
+	for i := 0; i+1 < len(bounds); i++ {
+		x, y := bounds[i], bounds[i+1]
+		if !x.Before(y) {
+			continue
+		}
+		if !excluded(exclusions, person, x, y) {
+			work(person, x, y)
+			continue
+		}
+		candidates := make([]string, len(people))
+		copy(candidates, people)
+		rank(candidates, tally, rng)
+		var (
+			who string
+			why blocked
+		)
+		for _, c := range candidates {
+			if c == person {
+				continue
+			}
+			if e, ok := exclusion(exclusions, c, x, y); ok {
+				why.add(c, fmt.Errorf("has an exclusion from %s to %s", e.StartAt, e.EndBefore))
+				continue
+			}
+			from := x
+			if worked.Person == c && worked.EndBefore.Equal(x) {
+				from = worked.StartAt
+			}
+			if err := track.check(c, from, y, a); err != nil {
+				why.add(c, err)
+				continue
+			}
+			if err := check(c); err != nil {
+				why.add(c, err)
+				continue
+			}
+			who = c
+			break
+		}
+		if who == "" {
+			if why == nil {
+				return nil, fmt.Errorf("nobody else available to cover %s from %s to %s", person, x, y)
+			}
+			return nil, fmt.Errorf("nobody available to cover %s from %s to %s: %s", person, x, y, why)
+		}
+		work(who, x, y)
+		w := model.Of(x, y)
+		tally[who] += w
+		tally[person] -= w
+		if n := len(out); n > 0 && out[n-1].Person == who && out[n-1].EndBefore.Equal(x) {
+			out[n-1].EndBefore = y
+			continue
+		}
+		out = append(out, save.Interval{
+			Person:    who,
+			Schedule:  exclusions[0].Schedule,
+			StartAt:   x,
+			EndBefore: y,
+			Kind:      save.IntervalKindShift,
+		})
+	}
+	if worked.Person != "" {
+		track.add(worked.Person, worked.StartAt, worked.EndBefore)
+	}
+	for w := range workers {
+		track.slot(w, a, b)
+	}
+	return out, nil
+}
+
+// exclusion finds an exclusion for person that overlaps [a, b).
+func exclusion(exclusions []save.Interval, person string, a, b time.Time) (save.Interval, bool) {
+	for _, e := range exclusions {
+		if e.Person == person && e.StartAt.Before(b) && e.EndBefore.After(a) {
+			return e, true
+		}
+	}
+	return save.Interval{}, false
+}
+
+func excluded(exclusions []save.Interval, person string, a, b time.Time) bool {
+	_, ok := exclusion(exclusions, person, a, b)
+	return ok
+}
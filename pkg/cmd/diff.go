@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/jreut/pager/v2/pkg/save"
+)
+
+const (
+	ChangeAdded      = "added"
+	ChangeRemoved    = "removed"
+	ChangeReassigned = "reassigned"
+)
+
+// A Change is a stretch of time when two timelines disagree about who is on call.
+type Change struct {
+	Kind      string
+	Layer     string
+	StartAt   time.Time
+	EndBefore time.Time
+	// Before is who was on call, or empty if nobody was.
+	Before string
+	// After is who is on call now, or empty if nobody is.
+	After string
+}
+
+// Diff compares two sets of flattened layers, like the ones from [ShowLayers].
+func Diff(before, after []save.Interval) []Change {
+	var (
+		layers []string
+		seen   = make(map[string]bool)
+	)
+	for _, xs := range [][]save.Interval{before, after} {
+		for _, x := range xs {
+			if !seen[x.Layer] {
+				seen[x.Layer] = true
+				layers = append(layers, x.Layer)
+			}
+		}
+	}
+	var out []Change
+	for _, l := range layers {
+		out = append(out, difflayer(l, inlayer(before, l), inlayer(after, l))...)
+	}
+	return out
+}
+
+func inlayer(xs []save.Interval, layer string) []save.Interval {
+	var out []save.Interval
+	for _, x := range xs {
+		if x.Layer == layer {
+			out = append(out, x)
+		}
+	}
+	return out
+}
+
+// difflayer compares two flattened timelines of the same layer.
+func difflayer(layer string, before, after []save.Interval) []Change {
+	var bounds []time.Time
+	for _, xs := range [][]save.Interval{before, after} {
+		for _, x := range xs {
+			bounds = append(bounds, x.StartAt, x.EndBefore)
+		}
+	}
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i].Before(bounds[j]) })
+
+	var out []Change
+	for i := 0; i+1 < len(bounds); i++ {
+		a, b := bounds[i], bounds[i+1]
+		if !a.Before(b) {
+			continue
+		}
+		c := Change{
+			Layer:     layer,
+			StartAt:   a,
+			EndBefore: b,
+			Before:    who(before, a),
+			After:     who(after, a),
+		}
+		switch {
+		case c.Before == c.After:
+			continue
+		case c.Before == "":
+			c.Kind = ChangeAdded
+		case c.After == "":
+			c.Kind = ChangeRemoved
+		default:
+			c.Kind = ChangeReassigned
+		}
+		if n := len(out); n > 0 {
+			last := &out[n-1]
+			if last.EndBefore.Equal(a) && last.Before == c.Before && last.After == c.After {
+				last.EndBefore = b
+				continue
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// who finds the person on call at t in a flattened timeline.
+func who(timeline []save.Interval, t time.Time) string {
+	for _, x := range timeline {
+		if !x.StartAt.After(t) && x.EndBefore.After(t) {
+			return x.Person
+		}
+	}
+	return ""
+}
+
+// WriteDiff writes one line per change for people to read.
+func WriteDiff(w io.Writer, changes []Change) error {
+	for _, c := range changes {
+		var line string
+		span := fmt.Sprintf("[%s, %s)", c.StartAt.Format(time.RFC3339), c.EndBefore.Format(time.RFC3339))
+		switch c.Kind {
+		case ChangeAdded:
+			line = fmt.Sprintf("+ %s %s%s", span, c.After, on(c.Layer))
+		case ChangeRemoved:
+			line = fmt.Sprintf("- %s %s%s", span, c.Before, on(c.Layer))
+		case ChangeReassigned:
+			line = fmt.Sprintf("~ %s %s -> %s%s", span, c.Before, c.After, on(c.Layer))
+		default:
+			panic(fmt.Sprintf("unhandled change %q", c.Kind))
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func WriteDiffCSV(w io.Writer, changes []Change) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"change", "start_at", "end_before", "before", "after", "layer"}); err != nil {
+		return err
+	}
+	for _, c := range changes {
+		if err := out.Write([]string{
+			c.Kind,
+			c.StartAt.Format(time.RFC3339),
+			c.EndBefore.Format(time.RFC3339),
+			c.Before,
+			c.After,
+			c.Layer,
+		}); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
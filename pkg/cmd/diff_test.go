@@ -0,0 +1,42 @@
+package cmd_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jreut/pager/v2/pkg/assert"
+	"github.com/jreut/pager/v2/pkg/cmd"
+	"github.com/jreut/pager/v2/pkg/save"
+)
+
+func TestDiff(t *testing.T) {
+	var (
+		t0 = time.Unix(0, 0).In(time.UTC)
+		t1 = t0.Add(1 * time.Hour)
+		t2 = t0.Add(2 * time.Hour)
+		t3 = t0.Add(3 * time.Hour)
+		t4 = t0.Add(4 * time.Hour)
+	)
+	shift := func(who string, a, b time.Time) save.Interval {
+		return save.Interval{Person: who, StartAt: a, EndBefore: b, Kind: save.IntervalKindShift}
+	}
+	before := []save.Interval{
+		shift("alice", t0, t2),
+		shift("bob", t2, t3),
+	}
+	after := []save.Interval{
+		shift("alice", t0, t1),
+		shift("cindy", t1, t3),
+		shift("cindy", t3, t4),
+	}
+	assert.Cmp(t, []cmd.Change{
+		{Kind: cmd.ChangeReassigned, StartAt: t1, EndBefore: t2, Before: "alice", After: "cindy"},
+		{Kind: cmd.ChangeReassigned, StartAt: t2, EndBefore: t3, Before: "bob", After: "cindy"},
+		{Kind: cmd.ChangeAdded, StartAt: t3, EndBefore: t4, After: "cindy"},
+	}, cmd.Diff(before, after))
+	assert.Cmp(t, []cmd.Change{
+		{Kind: cmd.ChangeRemoved, StartAt: t0, EndBefore: t2, Before: "alice"},
+		{Kind: cmd.ChangeRemoved, StartAt: t2, EndBefore: t3, Before: "bob"},
+	}, cmd.Diff(before, nil))
+	assert.Cmp(t, []cmd.Change(nil), cmd.Diff(before, before))
+}
@@ -3,27 +3,117 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/jreut/pager/v2/pkg/save"
 )
 
+// Action kinds that label people instead of changing their participation.
+// Other actions use the event kinds from [save].
+const (
+	ActionTag   = "TAG"
+	ActionUntag = "UNTAG"
+)
+
 type Action struct {
 	Kind string
 	Who  string
 	At   time.Time
+	// Tag is only used by ActionTag and ActionUntag.
+	Tag string
 }
 
+// EditSchedule applies the actions to the schedule.
+// Only people who are added to the schedule can shadow it, since shadowing holds them back from its shifts until they join.
 func EditSchedule(ctx context.Context, q *save.Queries, schedule string, actions []Action) error {
+	if err := shadowable(ctx, q, schedule, actions); err != nil {
+		return err
+	}
 	for _, action := range actions {
-		if err := q.AddEvent(ctx, save.AddEventParams{
-			Person:   action.Who,
-			Schedule: schedule,
-			Kind:     action.Kind,
-			At:       action.At,
-		}); err != nil {
-			return fmt.Errorf("adding participant %s(%s): %w", action.Kind, action.Who, err)
+		if err := known(ctx, q, action.Who); err != nil {
+			return err
+		}
+		switch action.Kind {
+		case ActionTag:
+			if err := q.AddTag(ctx, save.AddTagParams{
+				Person:   action.Who,
+				Schedule: schedule,
+				Tag:      action.Tag,
+			}); err != nil {
+				return fmt.Errorf("tagging %s with %q: %w", action.Who, action.Tag, err)
+			}
+		case ActionUntag:
+			if err := q.RemoveTag(ctx, save.RemoveTagParams{
+				Person:   action.Who,
+				Schedule: schedule,
+				Tag:      action.Tag,
+			}); err != nil {
+				return fmt.Errorf("untagging %s with %q: %w", action.Who, action.Tag, err)
+			}
+		default:
+			if err := q.AddEvent(ctx, save.AddEventParams{
+				Person:   action.Who,
+				Schedule: schedule,
+				Kind:     action.Kind,
+				At:       action.At,
+			}); err != nil {
+				return fmt.Errorf("adding participant %s(%s): %w", action.Kind, action.Who, err)
+			}
 		}
 	}
 	return nil
 }
+
+// shadowable explains who the actions let shadow the schedule without adding them to it, or returns nil if nobody.
+func shadowable(ctx context.Context, q *save.Queries, schedule string, actions []Action) error {
+	shadowed := make(map[string]bool)
+	for _, a := range actions {
+		if a.Kind == save.EventKindShadow {
+			shadowed[a.Who] = true
+		}
+	}
+	if len(shadowed) == 0 {
+		return nil
+	}
+	for _, a := range actions {
+		if a.Kind == save.EventKindAdd {
+			delete(shadowed, a.Who)
+		}
+	}
+	events, err := q.ListEvents(ctx, schedule)
+	if err != nil {
+		return err
+	}
+	for _, e := range events {
+		if e.Kind == save.EventKindAdd {
+			delete(shadowed, e.Person)
+		}
+	}
+	var missing []string
+	for p := range shadowed {
+		missing = append(missing, p)
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	return fmt.Errorf("add %s to schedule %q before they can shadow it", strings.Join(missing, ", "), schedule)
+}
+
+// Tags returns the set of tags for each person in the schedule.
+func Tags(ctx context.Context, q *save.Queries, schedule string) (map[string]map[string]bool, error) {
+	tags, err := q.ListTags(ctx, schedule)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]map[string]bool)
+	for _, t := range tags {
+		if out[t.Person] == nil {
+			out[t.Person] = make(map[string]bool)
+		}
+		out[t.Person][t.Tag] = true
+	}
+	return out, nil
+}
@@ -6,12 +6,15 @@ import (
 	"time"
 
 	"github.com/jreut/pager/v2/pkg/assert"
+	"github.com/jreut/pager/v2/pkg/cmd"
 	"github.com/jreut/pager/v2/pkg/save"
+	"github.com/jreut/pager/v2/pkg/save/savetest"
 )
 
 func TestEditSchedule(t *testing.T) {
 	ctx := context.Background()
 	q := save.New(testdb(t, ctx))
+	addpeople(t, ctx, q, "alice")
 
 	t0 := time.Unix(0, 0).In(time.UTC)
 
@@ -21,7 +24,7 @@ func TestEditSchedule(t *testing.T) {
 	)
 
 	// Can't add an interval for a nonexistent schedule.
-	assert.Error(t, "FOREIGN KEY constraint failed", q.AddInterval(ctx, save.AddIntervalParams{
+	assert.Error(t, savetest.ErrForeignKey, q.AddInterval(ctx, save.AddIntervalParams{
 		Person:    alice,
 		Schedule:  s1,
 		StartAt:   t0,
@@ -38,4 +41,48 @@ func TestEditSchedule(t *testing.T) {
 		EndBefore: t0.Add(time.Hour),
 		Kind:      save.IntervalKindShift,
 	}))
+
+	assert.Nil(t, cmd.EditSchedule(ctx, q, s1, []cmd.Action{
+		{Who: alice, Kind: cmd.ActionTag, Tag: "db"},
+		{Who: alice, Kind: cmd.ActionTag, Tag: "senior"},
+		// Tagging twice is ok.
+		{Who: alice, Kind: cmd.ActionTag, Tag: "db"},
+		{Who: alice, Kind: cmd.ActionUntag, Tag: "senior"},
+		// Removing a missing tag is ok too.
+		{Who: alice, Kind: cmd.ActionUntag, Tag: "network"},
+	}))
+	tags, err := cmd.Tags(ctx, q, s1)
+	assert.Nil(t, err)
+	assert.Cmp(t, map[string]map[string]bool{alice: {"db": true}}, tags)
+
+	assert.Error(t, savetest.ErrCheck, cmd.EditSchedule(ctx, q, s1, []cmd.Action{
+		{Who: alice, Kind: cmd.ActionTag, Tag: ""},
+	}))
+}
+
+func TestEditScheduleShadow(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+	addpeople(t, ctx, q, "alice", "bob")
+	const schedule = "schedule"
+	assert.Nil(t, q.AddSchedule(ctx, schedule))
+	t0 := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	// Shadowing alone would leave bob out of the schedule without a word.
+	assert.Error(t, `add bob to schedule "schedule" before they can shadow it`, cmd.EditSchedule(ctx, q, schedule, []cmd.Action{
+		{Who: "alice", At: t0, Kind: save.EventKindAdd},
+		{Who: "bob", At: t0.AddDate(0, 0, 7), Kind: save.EventKindShadow},
+	}))
+	events, err := q.ListEvents(ctx, schedule)
+	assert.Nil(t, err)
+	assert.Cmp(t, 0, len(events))
+
+	// Adding them in the same edit or an earlier one works.
+	assert.Nil(t, cmd.EditSchedule(ctx, q, schedule, []cmd.Action{
+		{Who: "alice", At: t0, Kind: save.EventKindAdd},
+		{Who: "alice", At: t0.AddDate(0, 0, 7), Kind: save.EventKindShadow},
+	}))
+	assert.Nil(t, cmd.EditSchedule(ctx, q, schedule, []cmd.Action{
+		{Who: "alice", At: t0.AddDate(0, 0, 14), Kind: save.EventKindShadow},
+	}))
 }
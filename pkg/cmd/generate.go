@@ -2,144 +2,422 @@ package cmd
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"math/rand"
 	"sort"
 	"time"
 
-	"github.com/jreut/pager/v2/pkg/global"
+	"github.com/jreut/pager/v2/pkg/cost"
 	"github.com/jreut/pager/v2/pkg/save"
 )
 
-var edt = func() *time.Location {
-	edt, err := time.LoadLocation("America/New_York")
+type GenerateParams struct {
+	Schedule string
+	// Style and StyleParams default to the schedule's, and nil StyleParams means none were given.
+	Style       string
+	StyleParams StyleParams
+	StartAt     time.Time
+	EndBefore   time.Time
+	// Lookback credits shifts in [StartAt-Lookback, StartAt) toward each person's tally.
+	// This keeps generating consecutive ranges as fair as generating them all at once.
+	// With a lookback, whoever joins after it starts begins level with the lowest tally of the people who joined before them.
+	// Zero counts nothing from before StartAt, and everyone starts even.
+	Lookback time.Duration
+	// Cost weighs each shift toward the tally.
+	// The zero value counts every hour the same.
+	// Generate adds the schedule's holidays to Cost.Holidays.
+	Cost cost.Model
+	// HolidayHandoffs hands off at the start and end of each holiday, on top of the style's handoffs.
+	HolidayHandoffs bool
+	// Append keeps shifts from earlier runs of Generate in [StartAt, EndBefore) underneath the new ones.
+	// Otherwise Generate replaces them.
+	// Either way, shifts added by hand stay on top.
+	Append bool
+	// Seed shuffles people with a pseudo-random number generator whenever their tallies tie.
+	// The same seed and inputs always give the same shifts.
+	// Zero breaks ties in order of name instead.
+	// The generate command only passes zero when DETERMINISTIC=1, and picks a random seed otherwise.
+	Seed int64
+	// Author records who generated the shifts.
+	Author string
+}
+
+// Generate fills the schedule with shifts during [StartAt, EndBefore).
+// A missing style or parameters come from the schedule's [Settings].
+func Generate(ctx context.Context, q *save.Queries, arg GenerateParams) error {
+	settings, err := GetSettings(ctx, q, arg.Schedule)
 	if err != nil {
-		panic(err)
+		return err
 	}
-	return edt
-}()
-
-const Style = "MondayAndFridayAtNoonEastern"
-
-func Generate(ctx context.Context, q *save.Queries, schedule string, style string, start, end time.Time) error {
-	switch style {
-	case Style:
-	default:
-		return fmt.Errorf("unhandled style %q", style)
+	arg.Style, arg.StyleParams, err = settings.style(arg.Style, arg.StyleParams)
+	if err != nil {
+		return err
 	}
+	rotation, err := NewRotation(arg.Style, arg.StyleParams)
+	if err != nil {
+		return err
+	}
+	schedule, start, end := arg.Schedule, arg.StartAt, arg.EndBefore
 	events, err := q.ListEvents(ctx, schedule)
 	if err != nil {
 		return err
 	}
+	if !arg.Append {
+		if err := q.ClearGenerated(ctx, save.ClearGeneratedParams{
+			Schedule:  schedule,
+			StartAt:   start,
+			EndBefore: end,
+		}); err != nil {
+			return fmt.Errorf("clearing earlier generated shifts: %w", err)
+		}
+	}
+	generation, err := q.AddGeneration(ctx, save.AddGenerationParams{
+		Schedule:  schedule,
+		Style:     arg.Style,
+		Params:    arg.StyleParams.String(),
+		StartAt:   start,
+		EndBefore: end,
+		Seed:      arg.Seed,
+	})
+	if err != nil {
+		return err
+	}
+	var rng *rand.Rand
+	if arg.Seed != 0 {
+		rng = rand.New(rand.NewSource(arg.Seed))
+	}
+	regional, _ := rotation.(RegionalRotation)
+	requirements, err := Requirements(ctx, q, schedule)
+	if err != nil {
+		return err
+	}
+	var tags map[string]map[string]bool
+	if regional != nil || len(requirements) > 0 {
+		tags, err = Tags(ctx, q, schedule)
+		if err != nil {
+			return err
+		}
+	}
+	holidays, err := Holidays(ctx, q, schedule, start.Add(-arg.Lookback), end)
+	if err != nil {
+		return err
+	}
+	layers, err := Layers(ctx, q, schedule)
+	if err != nil {
+		return err
+	}
+	constraints, err := GetConstraints(ctx, q, schedule)
+	if err != nil {
+		return err
+	}
+	// Each layer keeps to the constraints on its own,
+	// counting the shifts before the range and the ones that generating leaves in it, like manual ones.
+	tracks := make(map[string]*tracker)
+	for _, l := range layers {
+		tracks[l] = newtracker(constraints)
+		if constraints.zero() {
+			continue
+		}
+		previous, err := ShowLayer(ctx, q, schedule, l, start.Add(-Window), start)
+		if err != nil {
+			return fmt.Errorf("loading shifts to check constraints: %w", err)
+		}
+		for _, i := range previous {
+			tracks[l].add(i.Person, i.StartAt, i.EndBefore)
+			tracks[l].slot(i.Person, i.StartAt, i.EndBefore)
+		}
+		kept, err := ShowLayer(ctx, q, schedule, l, start, end)
+		if err != nil {
+			return fmt.Errorf("loading shifts to check constraints: %w", err)
+		}
+		for _, i := range kept {
+			tracks[l].add(i.Person, i.StartAt, i.EndBefore)
+		}
+	}
+	model := arg.Cost
+	model.Holidays = append(periods(holidays), model.Holidays...)
+	next := rotation.Next
+	if arg.HolidayHandoffs {
+		bounds := handoffs(holidays)
+		next = func(t time.Time) time.Time {
+			out := rotation.Next(t)
+			for _, h := range bounds {
+				if h.After(t) {
+					if h.Before(out) {
+						out = h
+					}
+					break
+				}
+			}
+			return out
+		}
+	}
 	available := make(map[string]bool)
-	tally := make(map[string]time.Duration)
-	a, b := start, nextShiftTransition(start)
+	// joined is when each available person was last added.
+	joined := make(map[string]time.Time)
+	shadows, err := q.ListShadows(ctx, schedule)
+	if err != nil {
+		return err
+	}
+	until := shadowing(shadows)
+	// Each layer in each region keeps its own tally.
+	// Rotations without regions use the empty region.
+	tallies := make(map[pool]map[string]time.Duration)
+	tallyof := func(p pool) map[string]time.Duration {
+		if tallies[p] == nil {
+			tallies[p] = make(map[string]time.Duration)
+		}
+		return tallies[p]
+	}
+	// leveled has who already started level with the rest of each pool.
+	leveled := make(map[pool]map[string]bool)
+	leveledof := func(p pool) map[string]bool {
+		if leveled[p] == nil {
+			leveled[p] = make(map[string]bool)
+		}
+		return leveled[p]
+	}
+	if arg.Lookback > 0 {
+		for _, l := range layers {
+			previous, err := ShowLayer(ctx, q, schedule, l, start.Add(-arg.Lookback), start)
+			if err != nil {
+				return fmt.Errorf("seeding tally: %w", err)
+			}
+			for _, i := range previous {
+				var region string
+				if regional != nil {
+					region = regional.Region(i.StartAt)
+				}
+				tallyof(pool{layer: l, region: region})[i.Person] += model.Of(i.StartAt, i.EndBefore)
+			}
+		}
+	}
+	a, b := start, next(start)
+	var seen int
 	for a.Before(end) {
 		if b.After(end) {
 			b = end
 		}
-		for _, e := range events {
-			if e.At.After(a) {
-				break
-			}
+		for ; seen < len(events) && !events[seen].At.After(a); seen++ {
+			e := events[seen]
 			switch e.Kind {
 			case save.EventKindAdd:
+				if !available[e.Person] {
+					joined[e.Person] = e.At
+					for _, l := range leveled {
+						delete(l, e.Person)
+					}
+				}
 				available[e.Person] = true
 			case save.EventKindRemove:
 				delete(available, e.Person)
+			case save.EventKindShadow:
+				// Handled by until above.
 			default:
 				panic(fmt.Sprintf("unhandled event %q", e.Kind))
 			}
 		}
-		var people []string
+		var region string
+		if regional != nil {
+			region = regional.Region(a)
+		}
+		var people, shadows []string
 		for p := range available {
+			if regional != nil && !tags[p][region] {
+				continue
+			}
+			if a.Before(until[p]) {
+				shadows = append(shadows, p)
+				continue
+			}
 			people = append(people, p)
 		}
-		sort.Slice(people, func(i, j int) bool {
-			if global.Deterministic() {
-				if tally[people[i]] < tally[people[j]] {
-					return true
-				}
-				if tally[people[i]] == tally[people[j]] {
-					return people[i] < people[j]
-				}
-				return false
-			}
-			return tally[people[i]] < tally[people[j]]
-		})
+		sort.Strings(shadows)
 		if len(people) == 0 {
+			if regional != nil {
+				return fmt.Errorf("nobody in region %q available to take shift from %s to %s", region, a, b)
+			}
 			return fmt.Errorf("nobody available to take shift from %s to %s", a, b)
 		}
-		person := people[0]
-		tally[person] += b.Sub(a)
-		params := save.AddIntervalParams{
-			Person:    person,
+		exclusions, err := q.ListIntervals(ctx, save.ListIntervalsParams{
 			Schedule:  schedule,
+			Kind:      save.IntervalKindExclusion,
 			StartAt:   a,
 			EndBefore: b,
-			Kind:      save.IntervalKindShift,
-		}
-		// Bypass cmd.AddInterval's conflict checks.
-		// We fix any exclusions for this person later in this function.
-		if err := AddInterval(ctx, q, params, true); err != nil {
-			return fmt.Errorf("inserting interval %+v: %w", params, err)
+		})
+		if err != nil {
+			return err
 		}
-		exclusions, err := q.ListIntervals(ctx, save.ListIntervalsParams{
+		preferences, err := q.ListIntervals(ctx, save.ListIntervalsParams{
 			Schedule:  schedule,
-			Kind:      save.IntervalKindExclusion,
+			Kind:      save.IntervalKindPreference,
 			StartAt:   a,
 			EndBefore: b,
 		})
 		if err != nil {
 			return err
 		}
-		// Find someone to cover each exclusion this person has during this interval.
-		for _, e := range exclusions {
-			if e.Person == person {
-				if len(people) < 2 {
-					return fmt.Errorf("nobody available to cover %s from %s to %s", person, a, b)
+		// Nobody takes part in two layers during the same shift, even as a cover.
+		taken := make(map[string]bool)
+		// team has the person on each layer, in order.
+		var team []string
+		for l, layer := range layers {
+			tally := tallyof(pool{layer: layer, region: region})
+			if arg.Lookback > 0 {
+				level(tally, people, joined, start.Add(-arg.Lookback), leveledof(pool{layer: layer, region: region}))
+			}
+			var candidates []string
+			for _, p := range people {
+				if !taken[p] {
+					candidates = append(candidates, p)
+				}
+			}
+			if len(candidates) == 0 {
+				return fmt.Errorf("nobody left to take shift%s from %s to %s", on(layer), a, b)
+			}
+			rank(candidates, prefer(tally, preferences, a, b, model), rng)
+			// Take the first person who doesn't break a constraint,
+			// and who leaves the later layers a way to meet the requirements.
+			track := tracks[layer]
+			var (
+				person string
+				why    blocked
+			)
+			for _, p := range candidates {
+				err := track.check(p, a, b, a)
+				if err == nil {
+					err = meets(requirements, tags, append(team, p), len(layers)-l-1)
+					if err != nil {
+						err = fmt.Errorf("would leave the shift with %w", err)
+					}
+				}
+				if err != nil {
+					why.add(p, err)
+					continue
+				}
+				person = p
+				break
+			}
+			if person == "" {
+				return fmt.Errorf("nobody can take shift%s from %s to %s without breaking a constraint: %s", on(layer), a, b, why)
+			}
+			team = append(team, person)
+			taken[person] = true
+			tally[person] += model.Of(a, b)
+		}
+		for l, layer := range layers {
+			person := team[l]
+			params := save.AddIntervalParams{
+				Person:    person,
+				Schedule:  schedule,
+				StartAt:   a,
+				EndBefore: b,
+				Kind:      save.IntervalKindShift,
+				Generation: sql.NullInt64{
+					Int64: generation,
+					Valid: true,
+				},
+				Layer:  layer,
+				Source: save.IntervalSourceGenerate,
+				Author: arg.Author,
+			}
+			// Bypass cmd.AddInterval's conflict checks.
+			// We fix any exclusions for this person next.
+			if err := AddInterval(ctx, q, params, true); err != nil {
+				return fmt.Errorf("inserting interval %+v: %w", params, err)
+			}
+			// Find people to cover the exclusions this person has during this interval.
+			var candidates []string
+			for _, p := range people {
+				if p == person || !taken[p] {
+					candidates = append(candidates, p)
+				}
+			}
+			// Covers stand in for this person alongside the rest of the team.
+			// cover checks them against the layer's constraints, and credits the layer's tracker with who works when.
+			check := func(c string) error {
+				covered := append([]string{c}, team[:l]...)
+				covered = append(covered, team[l+1:]...)
+				if err := meets(requirements, tags, covered, 0); err != nil {
+					return fmt.Errorf("would leave the shift with %w", err)
+				}
+				return nil
+			}
+			covers, err := cover(person, candidates, exclusions, a, b, tallyof(pool{layer: layer, region: region}), model, rng, tracks[layer], check)
+			if err != nil {
+				return err
+			}
+			for _, c := range covers {
+				taken[c.Person] = true
+				c.Generation, c.Layer = params.Generation, layer
+				c.Source, c.Author = params.Source, params.Author
+				params := c.Params()
+				if err := AddInterval(ctx, q, params, false); err != nil {
+					return fmt.Errorf("inserting interval %+v: %w", params, err)
 				}
+			}
+		}
+		// Shadows pair with whoever is on call until they stop shadowing, except when they are away.
+		for _, p := range shadows {
+			end := b
+			if until[p].Before(end) {
+				end = until[p]
+			}
+			for _, span := range free(p, exclusions, a, end) {
 				params := save.AddIntervalParams{
-					Person:    people[1],
-					Schedule:  schedule,
-					StartAt:   e.StartAt,
-					EndBefore: e.EndBefore,
-					Kind:      save.IntervalKindShift,
+					Person:     p,
+					Schedule:   schedule,
+					StartAt:    span[0],
+					EndBefore:  span[1],
+					Kind:       save.IntervalKindShadow,
+					Generation: sql.NullInt64{Int64: generation, Valid: true},
+					Source:     save.IntervalSourceGenerate,
+					Author:     arg.Author,
 				}
 				if err := AddInterval(ctx, q, params, false); err != nil {
 					return fmt.Errorf("inserting interval %+v: %w", params, err)
 				}
 			}
 		}
-		a, b = b, nextShiftTransition(b)
+		a, b = b, next(b)
 	}
 
 	return nil
 }
 
-func nextShiftTransition(t time.Time) time.Time {
-	noon := time.Date(t.Year(), t.Month(), t.Day(), 12, 0, 0, 0, edt)
-	switch t.In(edt).Weekday() {
-	case time.Sunday:
-		return noon.AddDate(0, 0, 1)
-	case time.Monday:
-		if !t.Before(noon) {
-			return noon.AddDate(0, 0, 4)
-		}
-		return noon
-	case time.Tuesday:
-		return noon.AddDate(0, 0, 3)
-	case time.Wednesday:
-		return noon.AddDate(0, 0, 2)
-	case time.Thursday:
-		return noon.AddDate(0, 0, 1)
-	case time.Friday:
-		if !t.Before(noon) {
-			return noon.AddDate(0, 0, 3)
-		}
-		return noon
-	case time.Saturday:
-		return noon.AddDate(0, 0, 2)
-	default:
-		panic(fmt.Sprintf("unhandled t.Weekday() %v", t.Weekday()))
+// pool names the people who share a tally.
+type pool struct{ layer, region string }
+
+// level starts whoever among people joined after since at the lowest tally of the people who joined before them.
+// Otherwise a newcomer has none of the time on call that the lookback credits to everyone else,
+// and takes every shift until they catch up.
+//
+// Each person levels once, when someone who joined before them is around to level with, and done remembers who did.
+func level(tally map[string]time.Duration, people []string, joined map[string]time.Time, since time.Time, done map[string]bool) {
+	for _, p := range people {
+		if done[p] {
+			continue
+		}
+		if !joined[p].After(since) {
+			done[p] = true
+			continue
+		}
+		var (
+			low   time.Duration
+			found bool
+		)
+		for _, o := range people {
+			if joined[o].Before(joined[p]) && (!found || tally[o] < low) {
+				low, found = tally[o], true
+			}
+		}
+		if !found {
+			continue
+		}
+		done[p] = true
+		if tally[p] < low {
+			tally[p] = low
+		}
 	}
 }
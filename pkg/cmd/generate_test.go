@@ -2,20 +2,27 @@ package cmd_test
 
 import (
 	"context"
+	"database/sql"
 	"encoding/csv"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/jreut/pager/v2/pkg/assert"
 	"github.com/jreut/pager/v2/pkg/cmd"
+	"github.com/jreut/pager/v2/pkg/cost"
+	"github.com/jreut/pager/v2/pkg/global"
+	"github.com/jreut/pager/v2/pkg/interval"
 	"github.com/jreut/pager/v2/pkg/save"
 )
 
 func TestGenerate(t *testing.T) {
-	t.Run(cmd.Style, func(t *testing.T) {
+	t.Run(cmd.StyleMondayAndFridayAtNoonEastern, func(t *testing.T) {
 		ctx := context.Background()
 		db := testdb(t, ctx)
 		q := save.New(db)
+		addpeople(t, ctx, q, "alice", "bob", "cindy")
 
 		const schedule = "schedule"
 		assert.Nil(t, q.AddSchedule(ctx, schedule))
@@ -63,20 +70,741 @@ func TestGenerate(t *testing.T) {
 			}))
 		}
 
-		assert.Nil(t, cmd.Generate(ctx, q, schedule, cmd.Style, generateStart, generateEnd))
+		assert.Nil(t, cmd.Generate(ctx, q, cmd.GenerateParams{
+			Schedule:  schedule,
+			Style:     cmd.StyleMondayAndFridayAtNoonEastern,
+			StartAt:   generateStart,
+			EndBefore: generateEnd,
+		}))
 		got, err := cmd.ShowSchedule(ctx, q, schedule, generateStart.AddDate(0, 0, -1), generateEnd.AddDate(0, 0, 1))
 		assert.Nil(t, err)
-		w := csv.NewWriter(assert.Golden(t, "generated.csv"))
-		t.Cleanup(func() {
-			w.Flush()
-			assert.Nil(t, w.Error())
+		golden(t, got)
+	})
+
+	for _, tt := range []struct {
+		label      string
+		style      string
+		params     string
+		cost       string
+		holidays   []save.Holiday
+		handoffs   bool
+		start, end time.Time
+	}{
+		{
+			// Europe/Berlin springs forward on 26 March 2023 and falls back on 29 October 2023.
+			label:  "Weekly_Berlin",
+			style:  cmd.StyleWeekly,
+			params: "day=Tuesday,at=10:00,tz=Europe/Berlin",
+			start:  time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC),
+			end:    time.Date(2023, 4, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// America/New_York falls back on 5 November 2023, and 01:30 happens twice that day.
+			label:  "Weekly_NewYork",
+			style:  cmd.StyleWeekly,
+			params: "day=sunday,at=01:30,tz=America/New_York",
+			start:  time.Date(2023, 10, 20, 0, 0, 0, 0, time.UTC),
+			end:    time.Date(2023, 11, 20, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			label:  "EveryNDays_UTC",
+			style:  cmd.StyleEveryNDays,
+			params: "days=3,at=09:00,from=2023-01-02",
+			start:  time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			end:    time.Date(2023, 1, 20, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// Fridays are heavy, so whoever takes one sits out the next few days.
+			label:  "EveryNDays_HeavyFridays",
+			style:  cmd.StyleEveryNDays,
+			params: "days=1",
+			cost:   "friday=4",
+			start:  time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+			end:    time.Date(2023, 1, 23, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// Holidays count triple, and handing off at their edges spreads them between people.
+			label:  "Weekly_Holidays",
+			style:  cmd.StyleWeekly,
+			params: "day=Monday,at=09:00",
+			cost:   "holiday=3",
+			holidays: []save.Holiday{
+				{
+					Name:      "Christmas Day",
+					StartAt:   time.Date(2023, 12, 25, 0, 0, 0, 0, time.UTC),
+					EndBefore: time.Date(2023, 12, 27, 0, 0, 0, 0, time.UTC),
+				},
+				{
+					Name:      "New Year's Day",
+					StartAt:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+					EndBefore: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			handoffs: true,
+			start:    time.Date(2023, 12, 18, 9, 0, 0, 0, time.UTC),
+			end:      time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC),
+		},
+	} {
+		t.Run(tt.label, func(t *testing.T) {
+			ctx := context.Background()
+			q := save.New(testdb(t, ctx))
+			addpeople(t, ctx, q, "alice", "bob")
+
+			const schedule = "schedule"
+			assert.Nil(t, q.AddSchedule(ctx, schedule))
+			assert.Nil(t, cmd.EditSchedule(ctx, q, schedule, []cmd.Action{
+				{Who: "alice", At: tt.start, Kind: save.EventKindAdd},
+				{Who: "bob", At: tt.start, Kind: save.EventKindAdd},
+			}))
+			for i := range tt.holidays {
+				tt.holidays[i].Schedule = schedule
+			}
+			assert.Nil(t, cmd.ImportHolidays(ctx, q, tt.holidays))
+			params, err := cmd.ParseStyleParams(tt.params)
+			assert.Nil(t, err)
+			m, err := cost.Parse(tt.cost)
+			assert.Nil(t, err)
+			assert.Nil(t, cmd.Generate(ctx, q, cmd.GenerateParams{
+				Schedule:        schedule,
+				Style:           tt.style,
+				StyleParams:     params,
+				StartAt:         tt.start,
+				EndBefore:       tt.end,
+				Cost:            m,
+				HolidayHandoffs: tt.handoffs,
+			}))
+			got, err := cmd.ShowSchedule(ctx, q, schedule, tt.start, tt.end)
+			assert.Nil(t, err)
+			golden(t, got)
 		})
-		for _, i := range got {
-			assert.Nil(t, w.Write([]string{
-				i.StartAt.Format(time.RFC3339),
-				i.EndBefore.Format(time.RFC3339),
-				i.Person,
+	}
+}
+
+func TestGenerateLookback(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+	addpeople(t, ctx, q, "alice", "bob", "cindy")
+
+	const (
+		together = "together"
+		split    = "split"
+	)
+	var (
+		start = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		// A Friday handoff, so no shift straddles the two halves.
+		middle = time.Date(2023, 2, 3, 12, 0, 0, 0, edt(t))
+		end    = time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+	)
+	for _, schedule := range []string{together, split} {
+		assert.Nil(t, q.AddSchedule(ctx, schedule))
+		assert.Nil(t, cmd.EditSchedule(ctx, q, schedule, []cmd.Action{
+			{Who: "alice", At: start, Kind: save.EventKindAdd},
+			{Who: "bob", At: start, Kind: save.EventKindAdd},
+			{Who: "cindy", At: start, Kind: save.EventKindAdd},
+		}))
+	}
+	generate := func(schedule string, start, end time.Time) {
+		t.Helper()
+		assert.Nil(t, cmd.Generate(ctx, q, cmd.GenerateParams{
+			Schedule:  schedule,
+			Style:     cmd.StyleMondayAndFridayAtNoonEastern,
+			StartAt:   start,
+			EndBefore: end,
+			Lookback:  90 * 24 * time.Hour,
+		}))
+	}
+	generate(together, start, end)
+	generate(split, start, middle)
+	generate(split, middle, end)
+
+	want, err := cmd.ShowSchedule(ctx, q, together, start, end)
+	assert.Nil(t, err)
+	got, err := cmd.ShowSchedule(ctx, q, split, start, end)
+	assert.Nil(t, err)
+	// Only the schedule, the IDs and the generations differ.
+	for i := range want {
+		want[i].Schedule = split
+		want[i].ID, want[i].Generation = 0, sql.NullInt64{}
+	}
+	for i := range got {
+		got[i].ID, got[i].Generation = 0, sql.NullInt64{}
+	}
+	assert.Cmp(t, want, got)
+}
+
+// TestGenerateNewcomer checks that someone who joins after the lookback starts doesn't take every shift until they catch up.
+func TestGenerateNewcomer(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+	addpeople(t, ctx, q, "alice", "bob", "cindy", "dave")
+	const schedule = "schedule"
+	assert.Nil(t, q.AddSchedule(ctx, schedule))
+	var (
+		start  = time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+		joins  = time.Date(2023, 4, 3, 0, 0, 0, 0, time.UTC)
+		end    = time.Date(2023, 5, 29, 0, 0, 0, 0, time.UTC)
+		params = cmd.StyleParams{"day": "Monday", "at": "00:00"}
+	)
+	assert.Nil(t, cmd.EditSchedule(ctx, q, schedule, []cmd.Action{
+		{Who: "alice", At: start, Kind: save.EventKindAdd},
+		{Who: "bob", At: start, Kind: save.EventKindAdd},
+		{Who: "cindy", At: start, Kind: save.EventKindAdd},
+		{Who: "dave", At: joins, Kind: save.EventKindAdd},
+	}))
+	generate := func(start, end time.Time) {
+		t.Helper()
+		assert.Nil(t, cmd.Generate(ctx, q, cmd.GenerateParams{
+			Schedule:    schedule,
+			Style:       cmd.StyleWeekly,
+			StyleParams: params,
+			StartAt:     start,
+			EndBefore:   end,
+			Lookback:    90 * 24 * time.Hour,
+		}))
+	}
+	generate(start, joins)
+	generate(joins, end)
+
+	got, err := cmd.ShowSchedule(ctx, q, schedule, joins, end)
+	assert.Nil(t, err)
+	assert.Cmp(t, 8, len(got))
+	shifts := make(map[string]int)
+	for _, i := range got {
+		shifts[i.Person]++
+	}
+	assert.Cmp(t, map[string]int{"alice": 2, "bob": 2, "cindy": 2, "dave": 2}, shifts)
+}
+
+func edt(t *testing.T) *time.Location {
+	t.Helper()
+	edt, err := time.LoadLocation("America/New_York")
+	assert.Nil(t, err)
+	return edt
+}
+
+func TestGenerateFollowTheSun(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+	addpeople(t, ctx, q, "alice", "bob", "cindy", "daria", "evan")
+
+	const schedule = "schedule"
+	assert.Nil(t, q.AddSchedule(ctx, schedule))
+	var (
+		start = time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+		end   = time.Date(2023, 1, 5, 0, 0, 0, 0, time.UTC)
+	)
+	var actions []cmd.Action
+	for who, region := range map[string]string{
+		"alice": "apac",
+		"bob":   "apac",
+		"cindy": "emea",
+		"daria": "emea",
+		"evan":  "amer",
+	} {
+		actions = append(actions,
+			cmd.Action{Who: who, At: start, Kind: save.EventKindAdd},
+			cmd.Action{Who: who, Kind: cmd.ActionTag, Tag: region},
+		)
+	}
+	assert.Nil(t, cmd.EditSchedule(ctx, q, schedule, actions))
+	params, err := cmd.ParseStyleParams("apac=23:00,emea=07:00,amer=15:00")
+	assert.Nil(t, err)
+	assert.Nil(t, cmd.Generate(ctx, q, cmd.GenerateParams{
+		Schedule:    schedule,
+		Style:       cmd.StyleFollowTheSun,
+		StyleParams: params,
+		StartAt:     start,
+		EndBefore:   end,
+	}))
+	got, err := cmd.ShowSchedule(ctx, q, schedule, start, end)
+	assert.Nil(t, err)
+	golden(t, got)
+
+	// Without anyone in a region, nobody can take its shifts.
+	assert.Nil(t, cmd.EditSchedule(ctx, q, schedule, []cmd.Action{
+		{Who: "evan", Kind: cmd.ActionUntag, Tag: "amer"},
+	}))
+	assert.Error(t, `nobody in region "amer" available`, cmd.Generate(ctx, q, cmd.GenerateParams{
+		Schedule:    schedule,
+		Style:       cmd.StyleFollowTheSun,
+		StyleParams: params,
+		StartAt:     start,
+		EndBefore:   end,
+	}))
+}
+
+func golden(t *testing.T, got []save.Interval) {
+	t.Helper()
+	w := csv.NewWriter(assert.Golden(t, "generated.csv"))
+	t.Cleanup(func() {
+		w.Flush()
+		assert.Nil(t, w.Error())
+	})
+	for _, i := range got {
+		assert.Nil(t, w.Write([]string{
+			i.StartAt.Format(time.RFC3339),
+			i.EndBefore.Format(time.RFC3339),
+			i.Person,
+		}))
+	}
+}
+
+func TestGenerateCover(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+	addpeople(t, ctx, q, "alice", "bob", "cindy")
+
+	const schedule = "schedule"
+	assert.Nil(t, q.AddSchedule(ctx, schedule))
+	var (
+		start = time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+		half  = start.AddDate(0, 0, 3)
+		end   = start.AddDate(0, 0, 7)
+	)
+	assert.Nil(t, cmd.EditSchedule(ctx, q, schedule, []cmd.Action{
+		{Who: "alice", At: start, Kind: save.EventKindAdd},
+		{Who: "bob", At: start, Kind: save.EventKindAdd},
+		{Who: "cindy", At: start, Kind: save.EventKindAdd},
+	}))
+	exclude := func(who string, start, end time.Time) {
+		t.Helper()
+		assert.Nil(t, q.AddInterval(ctx, save.AddIntervalParams{
+			Person:    who,
+			Schedule:  schedule,
+			StartAt:   start,
+			EndBefore: end,
+			Kind:      save.IntervalKindExclusion,
+		}))
+	}
+	params, err := cmd.ParseStyleParams("days=7,from=2023-01-02")
+	assert.Nil(t, err)
+	generate := func() error {
+		return cmd.Generate(ctx, q, cmd.GenerateParams{
+			Schedule:    schedule,
+			Style:       cmd.StyleEveryNDays,
+			StyleParams: params,
+			StartAt:     start,
+			EndBefore:   end,
+		})
+	}
+
+	// Alice takes the shift but is away all week, and bob is away for its first half.
+	// Cindy covers while bob is away, and bob covers the rest.
+	exclude("alice", start, end)
+	exclude("bob", start, half)
+	assert.Nil(t, generate())
+	got, err := cmd.ShowSchedule(ctx, q, schedule, start, end)
+	assert.Nil(t, err)
+	generation := sql.NullInt64{Int64: 1, Valid: true}
+	created := global.Now()
+	assert.Cmp(t, []save.Interval{
+		{ID: 4, Person: "cindy", Schedule: schedule, StartAt: start, EndBefore: half, Kind: save.IntervalKindShift, Generation: generation, Source: save.IntervalSourceGenerate, CreatedAt: created},
+		{ID: 5, Person: "bob", Schedule: schedule, StartAt: half, EndBefore: end, Kind: save.IntervalKindShift, Generation: generation, Source: save.IntervalSourceGenerate, CreatedAt: created},
+	}, got)
+
+	// Once cindy is away too, nobody can cover the day everyone is away.
+	exclude("cindy", start.AddDate(0, 0, 1), start.AddDate(0, 0, 2))
+	err = generate()
+	assert.Error(t, "nobody available to cover alice from 2023-01-03 .* to 2023-01-04 .*: bob has an exclusion from 2023-01-02 .* to 2023-01-05 .*; cindy has an exclusion from 2023-01-03 .* to 2023-01-04 ", err)
+}
+
+func TestGenerateReplaces(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+	addpeople(t, ctx, q, "alice", "bob", "cindy")
+
+	const schedule = "schedule"
+	assert.Nil(t, q.AddSchedule(ctx, schedule))
+	var (
+		start = time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+		end   = start.AddDate(0, 0, 28)
+	)
+	assert.Nil(t, cmd.EditSchedule(ctx, q, schedule, []cmd.Action{
+		{Who: "alice", At: start, Kind: save.EventKindAdd},
+		{Who: "bob", At: start, Kind: save.EventKindAdd},
+	}))
+	generate := func(style string, params cmd.StyleParams, start, end time.Time) {
+		t.Helper()
+		assert.Nil(t, cmd.Generate(ctx, q, cmd.GenerateParams{
+			Schedule:    schedule,
+			Style:       style,
+			StyleParams: params,
+			StartAt:     start,
+			EndBefore:   end,
+		}))
+	}
+	rows := func() int {
+		t.Helper()
+		got, err := q.ListIntervals(ctx, save.ListIntervalsParams{
+			Schedule:  schedule,
+			Kind:      save.IntervalKindShift,
+			StartAt:   start.AddDate(-1, 0, 0),
+			EndBefore: end.AddDate(1, 0, 0),
+		})
+		assert.Nil(t, err)
+		return len(got)
+	}
+	weekly := cmd.StyleParams{"day": "Monday"}
+
+	generate(cmd.StyleWeekly, weekly, start, end)
+	assert.Cmp(t, 4, rows())
+
+	// Generating again doesn't stack another set of shifts.
+	generate(cmd.StyleWeekly, weekly, start, end)
+	assert.Cmp(t, 4, rows())
+
+	// A manual override stays on top of later generations.
+	override := save.AddIntervalParams{
+		Person:    "cindy",
+		Schedule:  schedule,
+		StartAt:   start.AddDate(0, 0, 10),
+		EndBefore: start.AddDate(0, 0, 11),
+		Kind:      save.IntervalKindShift,
+	}
+	assert.Nil(t, q.AddInterval(ctx, override))
+	// Regenerating the middle of the month daily trims the weekly shifts around it.
+	middle, last := start.AddDate(0, 0, 9), start.AddDate(0, 0, 12)
+	generate(cmd.StyleEveryNDays, cmd.StyleParams{"days": "1"}, middle, last)
+	got, err := cmd.ShowSchedule(ctx, q, schedule, start, end)
+	assert.Nil(t, err)
+	var timeline []string
+	for _, i := range got {
+		timeline = append(timeline, fmt.Sprintf("%s %s", i.StartAt.Format("2006-01-02"), i.Person))
+	}
+	assert.Cmp(t, []string{
+		"2023-01-02 alice",
+		"2023-01-09 bob",
+		"2023-01-11 alice",
+		"2023-01-12 cindy",
+		"2023-01-13 alice",
+		"2023-01-14 bob",
+		"2023-01-16 alice",
+		"2023-01-23 bob",
+	}, timeline)
+	// The second week was split around the regenerated days.
+	assert.Cmp(t, 4+1+3+1, rows())
+}
+
+func TestGenerateLayers(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+	addpeople(t, ctx, q, "alice", "bob", "cindy")
+
+	const schedule = "schedule"
+	assert.Nil(t, q.AddSchedule(ctx, schedule))
+	assert.Nil(t, cmd.AddLayer(ctx, q, schedule, "secondary"))
+	assert.Error(t, "already has the primary layer", cmd.AddLayer(ctx, q, schedule, save.LayerPrimary))
+	var (
+		start = time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+		end   = start.AddDate(0, 0, 28)
+	)
+	assert.Nil(t, cmd.EditSchedule(ctx, q, schedule, []cmd.Action{
+		{Who: "alice", At: start, Kind: save.EventKindAdd},
+		{Who: "bob", At: start, Kind: save.EventKindAdd},
+		{Who: "cindy", At: start, Kind: save.EventKindAdd},
+	}))
+	// Alice is away for part of the second week, when she might be on either layer.
+	assert.Nil(t, q.AddInterval(ctx, save.AddIntervalParams{
+		Person:    "alice",
+		Schedule:  schedule,
+		StartAt:   start.AddDate(0, 0, 8),
+		EndBefore: start.AddDate(0, 0, 10),
+		Kind:      save.IntervalKindExclusion,
+	}))
+	assert.Nil(t, cmd.Generate(ctx, q, cmd.GenerateParams{
+		Schedule:    schedule,
+		Style:       cmd.StyleWeekly,
+		StyleParams: cmd.StyleParams{"day": "Monday"},
+		StartAt:     start,
+		EndBefore:   end,
+	}))
+	got, err := cmd.ShowLayers(ctx, q, schedule, start, end)
+	assert.Nil(t, err)
+	assert.Nil(t, interval.WriteCSV(assert.Golden(t, "layers.csv"), got, cmd.LayerColumn))
+
+	primary, err := cmd.ShowLayer(ctx, q, schedule, save.LayerPrimary, start, end)
+	assert.Nil(t, err)
+	secondary, err := cmd.ShowLayer(ctx, q, schedule, "secondary", start, end)
+	assert.Nil(t, err)
+	for _, p := range primary {
+		for _, s := range secondary {
+			if p.Person == s.Person && p.StartAt.Before(s.EndBefore) && s.StartAt.Before(p.EndBefore) {
+				t.Errorf("%s overlaps %s", p, s)
+			}
+		}
+	}
+
+	// Two layers need two people.
+	assert.Nil(t, cmd.EditSchedule(ctx, q, schedule, []cmd.Action{
+		{Who: "bob", At: start, Kind: save.EventKindRemove},
+		{Who: "cindy", At: start, Kind: save.EventKindRemove},
+	}))
+	assert.Error(t, `nobody left to take shift on layer "secondary"`, cmd.Generate(ctx, q, cmd.GenerateParams{
+		Schedule:    schedule,
+		Style:       cmd.StyleWeekly,
+		StyleParams: cmd.StyleParams{"day": "Monday"},
+		StartAt:     start,
+		EndBefore:   end,
+	}))
+}
+
+func TestGenerateShadows(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+	addpeople(t, ctx, q, "alice", "bob", "dan")
+
+	const schedule = "schedule"
+	assert.Nil(t, q.AddSchedule(ctx, schedule))
+	var (
+		start = time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+		joins = start.AddDate(0, 0, 10)
+		end   = start.AddDate(0, 0, 28)
+	)
+	assert.Nil(t, cmd.EditSchedule(ctx, q, schedule, []cmd.Action{
+		{Who: "alice", At: start, Kind: save.EventKindAdd},
+		{Who: "bob", At: start, Kind: save.EventKindAdd},
+		{Who: "dan", At: start, Kind: save.EventKindAdd},
+		{Who: "dan", At: joins, Kind: save.EventKindShadow},
+	}))
+	// Dan is away on the first day.
+	assert.Nil(t, q.AddInterval(ctx, save.AddIntervalParams{
+		Person:    "dan",
+		Schedule:  schedule,
+		StartAt:   start,
+		EndBefore: start.AddDate(0, 0, 1),
+		Kind:      save.IntervalKindExclusion,
+	}))
+	assert.Nil(t, cmd.Generate(ctx, q, cmd.GenerateParams{
+		Schedule:    schedule,
+		Style:       cmd.StyleWeekly,
+		StyleParams: cmd.StyleParams{"day": "Monday"},
+		StartAt:     start,
+		EndBefore:   end,
+	}))
+	got, err := cmd.ShowSchedule(ctx, q, schedule, start, end)
+	assert.Nil(t, err)
+	shadows, err := cmd.ShowShadows(ctx, q, schedule, start, end)
+	assert.Nil(t, err)
+	assert.Nil(t, interval.WriteCSV(assert.Golden(t, "shadows.csv"), append(got, shadows...), cmd.KindColumn))
+	for _, s := range got {
+		if s.Person == "dan" && s.StartAt.Before(joins) {
+			t.Errorf("dan is on call while shadowing: %s", s)
+		}
+	}
+}
+
+func TestGenerateShadowsShortened(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+	addpeople(t, ctx, q, "alice", "dan")
+
+	const schedule = "schedule"
+	assert.Nil(t, q.AddSchedule(ctx, schedule))
+	var (
+		start = time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+		joins = start.AddDate(0, 0, 7)
+		end   = start.AddDate(0, 0, 28)
+	)
+	assert.Nil(t, cmd.EditSchedule(ctx, q, schedule, []cmd.Action{
+		{Who: "alice", At: start, Kind: save.EventKindAdd},
+		{Who: "dan", At: start, Kind: save.EventKindAdd},
+		{Who: "dan", At: start.AddDate(0, 0, 21), Kind: save.EventKindShadow},
+	}))
+	// Dan is ready sooner than planned.
+	assert.Nil(t, cmd.EditSchedule(ctx, q, schedule, []cmd.Action{
+		{Who: "dan", At: joins, Kind: save.EventKindShadow},
+	}))
+	assert.Nil(t, cmd.Generate(ctx, q, cmd.GenerateParams{
+		Schedule:    schedule,
+		Style:       cmd.StyleWeekly,
+		StyleParams: cmd.StyleParams{"day": "Monday"},
+		StartAt:     start,
+		EndBefore:   end,
+	}))
+	shadows, err := cmd.ShowShadows(ctx, q, schedule, start, end)
+	assert.Nil(t, err)
+	var got []string
+	for _, s := range shadows {
+		got = append(got, fmt.Sprintf("%s %s %s", s.StartAt.Format("2006-01-02"), s.EndBefore.Format("2006-01-02"), s.Person))
+	}
+	assert.Cmp(t, []string{"2023-01-02 2023-01-09 dan"}, got)
+
+	// A clone keeps the shorter shadow.
+	assert.Nil(t, cmd.CloneSchedule(ctx, q, schedule, "clone", start))
+	events, err := q.ListShadows(ctx, "clone")
+	assert.Nil(t, err)
+	assert.Cmp(t, joins, events[len(events)-1].At)
+}
+
+func TestGeneratePreferences(t *testing.T) {
+	var (
+		start = time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+		week  = start.AddDate(0, 0, 7)
+		end   = start.AddDate(0, 0, 14)
+	)
+	for _, tt := range []struct {
+		label       string
+		preferences []save.AddIntervalParams
+		want        []string
+	}{
+		{
+			label: "none",
+			want:  []string{"alice", "bob"},
+		},
+		{
+			label: "tie-breaker",
+			preferences: []save.AddIntervalParams{
+				{Person: "alice", StartAt: start, EndBefore: week, Weight: -0.1},
+			},
+			want: []string{"bob", "alice"},
+		},
+		{
+			label: "asking",
+			preferences: []save.AddIntervalParams{
+				{Person: "bob", StartAt: start, EndBefore: week, Weight: 0.1},
+			},
+			want: []string{"bob", "alice"},
+		},
+		{
+			// Alice would rather skip both weeks, but somebody has to take one.
+			label: "soft",
+			preferences: []save.AddIntervalParams{
+				{Person: "alice", StartAt: start, EndBefore: end, Weight: -1},
+			},
+			want: []string{"bob", "alice"},
+		},
+	} {
+		t.Run(tt.label, func(t *testing.T) {
+			ctx := context.Background()
+			q := save.New(testdb(t, ctx))
+			addpeople(t, ctx, q, "alice", "bob")
+			const schedule = "schedule"
+			assert.Nil(t, q.AddSchedule(ctx, schedule))
+			assert.Nil(t, cmd.EditSchedule(ctx, q, schedule, []cmd.Action{
+				{Who: "alice", At: start, Kind: save.EventKindAdd},
+				{Who: "bob", At: start, Kind: save.EventKindAdd},
 			}))
+			for _, p := range tt.preferences {
+				p.Schedule, p.Kind = schedule, save.IntervalKindPreference
+				assert.Nil(t, cmd.AddInterval(ctx, q, p, false))
+			}
+			assert.Nil(t, cmd.Generate(ctx, q, cmd.GenerateParams{
+				Schedule:    schedule,
+				Style:       cmd.StyleWeekly,
+				StyleParams: cmd.StyleParams{"day": "Monday"},
+				StartAt:     start,
+				EndBefore:   end,
+			}))
+			got, err := cmd.ShowSchedule(ctx, q, schedule, start, end)
+			assert.Nil(t, err)
+			var people []string
+			for _, i := range got {
+				people = append(people, i.Person)
+			}
+			assert.Cmp(t, tt.want, people)
+		})
+	}
+}
+
+func TestGenerateSeed(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+	addpeople(t, ctx, q, "alice", "bob", "cindy", "daria", "evan", "felix")
+
+	var (
+		start = time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+		end   = start.AddDate(0, 0, 56)
+	)
+	generate := func(schedule string, seed int64) []string {
+		t.Helper()
+		assert.Nil(t, q.AddSchedule(ctx, schedule))
+		var actions []cmd.Action
+		for _, who := range []string{"alice", "bob", "cindy", "daria", "evan", "felix"} {
+			actions = append(actions, cmd.Action{Who: who, At: start, Kind: save.EventKindAdd})
 		}
-	})
+		assert.Nil(t, cmd.EditSchedule(ctx, q, schedule, actions))
+		assert.Nil(t, cmd.Generate(ctx, q, cmd.GenerateParams{
+			Schedule:    schedule,
+			Style:       cmd.StyleWeekly,
+			StyleParams: cmd.StyleParams{"day": "Monday"},
+			StartAt:     start,
+			EndBefore:   end,
+			Seed:        seed,
+		}))
+		got, err := cmd.ShowSchedule(ctx, q, schedule, start, end)
+		assert.Nil(t, err)
+		var people []string
+		for _, i := range got {
+			people = append(people, i.Person)
+		}
+		generations, err := q.ListGenerations(ctx, schedule)
+		assert.Nil(t, err)
+		assert.Cmp(t, seed, generations[len(generations)-1].Seed)
+		return people
+	}
+
+	byname := generate("byname", 0)
+	assert.Cmp(t, []string{"alice", "bob", "cindy", "daria", "evan", "felix", "alice", "bob"}, byname)
+	a := generate("a", 42)
+	assert.Cmp(t, a, generate("again", 42))
+	if b := generate("b", 43); cmp.Equal(a, b) {
+		t.Errorf("seeds 42 and 43 both gave %v", a)
+	}
+}
+
+func TestGenerateRequirements(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+	addpeople(t, ctx, q, "alice", "bob", "cindy", "dan")
+
+	const schedule = "schedule"
+	assert.Nil(t, q.AddSchedule(ctx, schedule))
+	assert.Nil(t, cmd.AddLayer(ctx, q, schedule, "secondary"))
+	var (
+		start = time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+		end   = start.AddDate(0, 0, 28)
+	)
+	assert.Nil(t, cmd.EditSchedule(ctx, q, schedule, []cmd.Action{
+		{Who: "alice", At: start, Kind: save.EventKindAdd},
+		{Who: "bob", At: start, Kind: save.EventKindAdd},
+		{Who: "cindy", At: start, Kind: save.EventKindAdd},
+		{Who: "dan", At: start, Kind: save.EventKindAdd},
+		{Who: "alice", Kind: cmd.ActionTag, Tag: "senior"},
+		{Who: "bob", Kind: cmd.ActionTag, Tag: "senior"},
+		{Who: "cindy", Kind: cmd.ActionTag, Tag: "junior"},
+		{Who: "dan", Kind: cmd.ActionTag, Tag: "junior"},
+	}))
+	assert.Nil(t, cmd.SetRequirement(ctx, q, schedule, cmd.Requirement{Tag: "senior", AtLeast: 1, AtMost: -1}))
+	assert.Nil(t, cmd.SetRequirement(ctx, q, schedule, cmd.Requirement{Tag: "junior", AtMost: 1}))
+	// Alice is away for part of the first week, so her cover has to be senior too.
+	assert.Nil(t, q.AddInterval(ctx, save.AddIntervalParams{
+		Person:    "alice",
+		Schedule:  schedule,
+		StartAt:   start.AddDate(0, 0, 1),
+		EndBefore: start.AddDate(0, 0, 3),
+		Kind:      save.IntervalKindExclusion,
+	}))
+	params := cmd.GenerateParams{
+		Schedule:    schedule,
+		Style:       cmd.StyleWeekly,
+		StyleParams: cmd.StyleParams{"day": "Monday"},
+		StartAt:     start,
+		EndBefore:   end,
+	}
+	assert.Nil(t, cmd.Generate(ctx, q, params))
+	violations, err := cmd.Validate(ctx, q, schedule, start, end)
+	assert.Nil(t, err)
+	assert.Cmp(t, []cmd.Violation(nil), violations)
+
+	// Without Bob, nobody senior can cover for Alice.
+	assert.Nil(t, cmd.EditSchedule(ctx, q, schedule, []cmd.Action{
+		{Who: "bob", At: start, Kind: save.EventKindRemove},
+	}))
+	assert.Error(t, `nobody available to cover alice .*would leave the shift with`, cmd.Generate(ctx, q, params))
+
+	// Without Alice either, only juniors are left, and they can't take both layers.
+	assert.Nil(t, cmd.EditSchedule(ctx, q, schedule, []cmd.Action{
+		{Who: "alice", At: start, Kind: save.EventKindRemove},
+	}))
+	assert.Error(t, `nobody can take shift on layer "secondary" from .* dan would leave the shift with 2 people tagged "junior" \(max 1\)`, cmd.Generate(ctx, q, params))
 }
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jreut/pager/v2/pkg/global"
+	"github.com/jreut/pager/v2/pkg/save"
+)
+
+// A Changeset is everything one command changed, as recorded by [Record].
+type Changeset struct {
+	ID      int64
+	Command string
+	Args    []string
+	Author  string
+	At      time.Time
+	// Changes counts the rows the command touched.
+	Changes int64
+	// Undoes is the changeset this one undid with [Undo], or zero.
+	Undoes int64
+	// UndoneBy is the changeset that undid this one, or zero.
+	UndoneBy int64
+}
+
+// Record runs f, recording every row it touches as one changeset.
+// Commands that don't change anything leave no changeset behind.
+func Record(ctx context.Context, q *save.Queries, command string, args []string, author string, f func() error) error {
+	encoded, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	id, err := q.OpenChangeset(ctx, save.OpenChangesetParams{
+		Command: command,
+		Args:    string(encoded),
+		Author:  author,
+		At:      global.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("opening changeset: %w", err)
+	}
+	if err := f(); err != nil {
+		return err
+	}
+	n, err := q.CountChanges(ctx, id)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return q.DeleteChangeset(ctx, id)
+	}
+	return q.CloseChangeset(ctx, id)
+}
+
+// History returns every changeset, oldest first.
+func History(ctx context.Context, q *save.Queries) ([]Changeset, error) {
+	css, err := q.ListChangesets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []Changeset
+	byid := make(map[int64]int)
+	for _, cs := range css {
+		if cs.Open {
+			continue
+		}
+		c := Changeset{
+			ID:      cs.ID,
+			Command: cs.Command,
+			Author:  cs.Author,
+			At:      cs.At,
+			Undoes:  cs.Undoes.Int64,
+		}
+		if err := json.Unmarshal([]byte(cs.Args), &c.Args); err != nil {
+			return nil, fmt.Errorf("parsing args of changeset #%d: %w", cs.ID, err)
+		}
+		c.Changes, err = q.CountChanges(ctx, cs.ID)
+		if err != nil {
+			return nil, err
+		}
+		byid[c.ID] = len(out)
+		out = append(out, c)
+	}
+	// An undo that was itself undone no longer counts.
+	// Undos come after what they undo, so walk backwards to see them first.
+	for i := len(out) - 1; i >= 0; i-- {
+		if c := out[i]; c.Undoes != 0 && c.UndoneBy == 0 {
+			out[byid[c.Undoes]].UndoneBy = c.ID
+		}
+	}
+	return out, nil
+}
+
+// Undo reverses every change in the changeset, as part of the changeset in progress.
+// Undoing an undo redoes the original changes.
+//
+// It refuses to undo changes to rows that a later changeset touched too, since that would clobber the later changes.
+func Undo(ctx context.Context, q *save.Queries, id int64) error {
+	cs, err := q.GetChangeset(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("no changeset #%d", id)
+	}
+	if err != nil {
+		return err
+	}
+	if cs.Open {
+		return fmt.Errorf("changeset #%d is still in progress", id)
+	}
+	history, err := History(ctx, q)
+	if err != nil {
+		return err
+	}
+	byid := make(map[int64]Changeset)
+	for _, c := range history {
+		byid[c.ID] = c
+	}
+	if by := byid[id].UndoneBy; by != 0 {
+		return fmt.Errorf("changeset #%d was already undone by #%d", id, by)
+	}
+	later, err := q.ChangedSince(ctx, id)
+	if err != nil {
+		return err
+	}
+	var ids []string
+	for _, l := range later {
+		c := byid[l]
+		// Later changes that were undone since, along with the undos themselves, left the rows as they were.
+		if c.UndoneBy != 0 || (c.Undoes > id && byid[c.Undoes].UndoneBy == c.ID) {
+			continue
+		}
+		ids = append(ids, fmt.Sprintf("#%d", l))
+	}
+	if len(ids) > 0 {
+		return fmt.Errorf("%w: later changesets changed the same rows as #%d (%s): undo them first", ErrConflict, id, strings.Join(ids, ", "))
+	}
+	changes, err := q.ListChanges(ctx, id)
+	if err != nil {
+		return err
+	}
+	for i := len(changes) - 1; i >= 0; i-- {
+		if err := q.Revert(ctx, changes[i]); err != nil {
+			return err
+		}
+	}
+	return q.SetUndoes(ctx, sql.NullInt64{Int64: id, Valid: true})
+}
+
+// WriteHistory writes changesets as a CSV, with each command's arguments separated by spaces.
+func WriteHistory(w io.Writer, history []Changeset) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"id", "at", "author", "command", "args", "changes", "undoes", "undone_by"}); err != nil {
+		return err
+	}
+	id := func(i int64) string {
+		if i == 0 {
+			return ""
+		}
+		return strconv.FormatInt(i, 10)
+	}
+	for _, c := range history {
+		if err := out.Write([]string{
+			id(c.ID),
+			c.At.Format(time.RFC3339),
+			c.Author,
+			c.Command,
+			strings.Join(c.Args, " "),
+			strconv.FormatInt(c.Changes, 10),
+			id(c.Undoes),
+			id(c.UndoneBy),
+		}); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// WriteChanges writes the rows a changeset touched as a CSV, with each row as it was before and after as JSON.
+func WriteChanges(w io.Writer, changes []save.Change) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"table", "op", "row", "old", "new"}); err != nil {
+		return err
+	}
+	for _, c := range changes {
+		if err := out.Write([]string{c.TableName, c.Op, strconv.FormatInt(c.RowID, 10), c.Old.String, c.New.String}); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,95 @@
+package cmd_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jreut/pager/v2/pkg/assert"
+	"github.com/jreut/pager/v2/pkg/cmd"
+	"github.com/jreut/pager/v2/pkg/save"
+)
+
+func TestUndo(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+	addpeople(t, ctx, q, "alice", "bob", "cindy")
+
+	const schedule = "schedule"
+	assert.Nil(t, q.AddSchedule(ctx, schedule))
+	day := func(n int) time.Time { return time.Date(2023, 1, 1+n, 0, 0, 0, 0, time.UTC) }
+	assert.Nil(t, cmd.EditSchedule(ctx, q, schedule, []cmd.Action{
+		{Kind: save.EventKindAdd, Who: "alice", At: day(0)},
+		{Kind: save.EventKindAdd, Who: "bob", At: day(0)},
+		{Kind: save.EventKindAdd, Who: "cindy", At: day(0)},
+	}))
+	generate := func(style string, params cmd.StyleParams) func() error {
+		return func() error {
+			return cmd.Generate(ctx, q, cmd.GenerateParams{
+				Schedule:    schedule,
+				Style:       style,
+				StyleParams: params,
+				StartAt:     day(0),
+				EndBefore:   day(14),
+			})
+		}
+	}
+	list := func() []save.Interval {
+		t.Helper()
+		got, err := q.ListIntervals(ctx, save.ListIntervalsParams{
+			Schedule:  schedule,
+			Kind:      save.IntervalKindShift,
+			StartAt:   day(0),
+			EndBefore: day(14),
+		})
+		assert.Nil(t, err)
+		return got
+	}
+
+	assert.Nil(t, cmd.Record(ctx, q, "generate", []string{"weekly"}, "tester", generate(cmd.StyleWeekly, cmd.StyleParams{"day": "Sunday"})))
+	want := list()
+	// Generating again over part of the range trims, deletes and inserts shifts.
+	assert.Nil(t, cmd.Record(ctx, q, "generate", []string{"daily"}, "tester", func() error {
+		return cmd.Generate(ctx, q, cmd.GenerateParams{
+			Schedule:    schedule,
+			Style:       cmd.StyleEveryNDays,
+			StyleParams: cmd.StyleParams{"days": "1"},
+			StartAt:     day(3),
+			EndBefore:   day(10),
+		})
+	}))
+	ids := func() []int64 {
+		t.Helper()
+		history, err := cmd.History(ctx, q)
+		assert.Nil(t, err)
+		var ids []int64
+		for _, c := range history {
+			ids = append(ids, c.ID)
+		}
+		return ids
+	}
+	// Reading doesn't leave a changeset behind.
+	assert.Nil(t, cmd.Record(ctx, q, "show-schedule", nil, "tester", func() error { list(); return nil }))
+	assert.Cmp(t, []int64{1, 2}, ids())
+
+	// SQLite gives the next changeset the ID that the empty one had, and PostgreSQL doesn't, so look it up.
+	assert.Nil(t, cmd.Record(ctx, q, "undo", []string{"2"}, "tester", func() error { return cmd.Undo(ctx, q, 2) }))
+	assert.Cmp(t, want, list())
+	undo := ids()[2]
+	assert.Error(t, fmt.Sprintf("changeset #2 was already undone by #%d", undo), cmd.Undo(ctx, q, 2))
+	assert.Error(t, "no changeset #99", cmd.Undo(ctx, q, 99))
+
+	// Undoing the first generate would clobber the undo of the second.
+	assert.Nil(t, cmd.Record(ctx, q, "undo", []string{fmt.Sprint(undo)}, "tester", func() error { return cmd.Undo(ctx, q, undo) }))
+	assert.Error(t, `later changesets changed the same rows as #1 \(#2\)`, cmd.Undo(ctx, q, 1))
+
+	history, err := cmd.History(ctx, q)
+	assert.Nil(t, err)
+	var got [][3]int64
+	for _, c := range history {
+		got = append(got, [3]int64{c.ID, c.Undoes, c.UndoneBy})
+	}
+	redo := ids()[3]
+	assert.Cmp(t, [][3]int64{{1, 0, 0}, {2, 0, 0}, {undo, 2, redo}, {redo, undo, 0}}, got)
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jreut/pager/v2/pkg/cost"
+	"github.com/jreut/pager/v2/pkg/interval"
+	"github.com/jreut/pager/v2/pkg/save"
+)
+
+// ImportHolidays adds holidays to their schedules, replacing any that start at the same time.
+func ImportHolidays(ctx context.Context, q *save.Queries, holidays []save.Holiday) error {
+	for _, h := range holidays {
+		if err := q.AddHoliday(ctx, save.AddHolidayParams(h)); err != nil {
+			return fmt.Errorf("adding holiday %q on %s: %w", h.Name, h.StartAt.Format(time.RFC3339), err)
+		}
+	}
+	return nil
+}
+
+// Holidays lists the holidays in the schedule that overlap [start, end).
+func Holidays(ctx context.Context, q *save.Queries, schedule string, start, end time.Time) ([]save.Holiday, error) {
+	all, err := q.ListHolidays(ctx, schedule)
+	if err != nil {
+		return nil, err
+	}
+	var out []save.Holiday
+	for _, h := range all {
+		if h.StartAt.Before(end) && h.EndBefore.After(start) {
+			out = append(out, h)
+		}
+	}
+	return out, nil
+}
+
+// HolidayColumn names the holidays during each interval.
+func HolidayColumn(holidays []save.Holiday) interval.Column {
+	return interval.Column{
+		Name: "holiday",
+		Value: func(i save.Interval) string {
+			var out []string
+			for _, h := range holidays {
+				if h.StartAt.Before(i.EndBefore) && h.EndBefore.After(i.StartAt) {
+					out = append(out, h.Name)
+				}
+			}
+			return strings.Join(out, "; ")
+		},
+	}
+}
+
+func periods(holidays []save.Holiday) []cost.Period {
+	var out []cost.Period
+	for _, h := range holidays {
+		out = append(out, cost.Period{StartAt: h.StartAt, EndBefore: h.EndBefore})
+	}
+	return out
+}
+
+// handoffs lists the starts and ends of the holidays, in order.
+func handoffs(holidays []save.Holiday) []time.Time {
+	var out []time.Time
+	for _, h := range holidays {
+		out = append(out, h.StartAt, h.EndBefore)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Before(out[j]) })
+	return out
+}
@@ -0,0 +1,52 @@
+package cmd_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jreut/pager/v2/pkg/assert"
+	"github.com/jreut/pager/v2/pkg/cmd"
+	"github.com/jreut/pager/v2/pkg/interval"
+	"github.com/jreut/pager/v2/pkg/save"
+)
+
+func TestHolidays(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+	addpeople(t, ctx, q, "alice", "bob")
+
+	const schedule = "schedule"
+	assert.Nil(t, q.AddSchedule(ctx, schedule))
+	var (
+		christmas = time.Date(2023, 12, 25, 0, 0, 0, 0, time.UTC)
+		newyear   = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	)
+	assert.Nil(t, cmd.ImportHolidays(ctx, q, []save.Holiday{
+		{Schedule: schedule, Name: "Xmas", StartAt: christmas, EndBefore: christmas.AddDate(0, 0, 1)},
+		{Schedule: schedule, Name: "New Year's Day", StartAt: newyear, EndBefore: newyear.AddDate(0, 0, 1)},
+	}))
+	// Importing again replaces holidays that start at the same time.
+	assert.Nil(t, cmd.ImportHolidays(ctx, q, []save.Holiday{
+		{Schedule: schedule, Name: "Christmas Day", StartAt: christmas, EndBefore: christmas.AddDate(0, 0, 2)},
+	}))
+
+	got, err := cmd.Holidays(ctx, q, schedule, christmas.AddDate(0, 0, 1), newyear)
+	assert.Nil(t, err)
+	assert.Cmp(t, []save.Holiday{
+		{Schedule: schedule, Name: "Christmas Day", StartAt: christmas, EndBefore: christmas.AddDate(0, 0, 2)},
+	}, got)
+
+	all, err := cmd.Holidays(ctx, q, schedule, christmas, newyear.AddDate(0, 0, 7))
+	assert.Nil(t, err)
+	var buf bytes.Buffer
+	assert.Nil(t, interval.WriteCSV(&buf, []save.Interval{
+		{Person: "alice", StartAt: christmas.AddDate(0, 0, -7), EndBefore: christmas},
+		{Person: "bob", StartAt: christmas, EndBefore: newyear.Add(time.Hour)},
+	}, cmd.HolidayColumn(all)))
+	assert.Cmp(t, `start_at,end_before,person,holiday
+2023-12-18T00:00:00Z,2023-12-25T00:00:00Z,alice,
+2023-12-25T00:00:00Z,2024-01-01T01:00:00Z,bob,Christmas Day; New Year's Day
+`, buf.String())
+}
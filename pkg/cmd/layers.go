@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jreut/pager/v2/pkg/interval"
+	"github.com/jreut/pager/v2/pkg/save"
+)
+
+// AddLayer adds a layer to the schedule after its existing layers, such as a secondary on-call.
+func AddLayer(ctx context.Context, q *save.Queries, schedule, name string) error {
+	if name == save.LayerPrimary {
+		return fmt.Errorf("every schedule already has the primary layer")
+	}
+	return q.AddLayer(ctx, save.AddLayerParams{Schedule: schedule, Name: name})
+}
+
+// Layers lists the schedule's layers in order, starting with [save.LayerPrimary].
+func Layers(ctx context.Context, q *save.Queries, schedule string) ([]string, error) {
+	layers, err := q.ListLayers(ctx, schedule)
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{save.LayerPrimary}, layers...), nil
+}
+
+// LayerColumn names the layer of each interval, leaving it empty for the primary layer.
+var LayerColumn = interval.Column{
+	Name:  "layer",
+	Value: func(i save.Interval) string { return i.Layer },
+}
+
+// on describes a layer for error messages, leaving the primary layer unsaid.
+func on(layer string) string {
+	if layer == save.LayerPrimary {
+		return ""
+	}
+	return fmt.Sprintf(" on layer %q", layer)
+}
@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jreut/pager/v2/pkg/save"
+)
+
+// A Person can join schedules, take shifts and be tagged.
+// Everyone has to be added with [AddPerson] first, so a typo can't put a phantom person on call.
+type Person struct {
+	// Name is how schedules, shifts and tags refer to the person.
+	Name        string
+	DisplayName string
+	Email       string
+	// Timezone names the person's location, like America/New_York.
+	Timezone string
+	// Identities maps each destination, like "opsgenie", to who the person is there.
+	// Destinations without an identity use Name.
+	Identities map[string]string
+}
+
+func (p Person) check() error {
+	if p.Email != "" {
+		if _, err := mail.ParseAddress(p.Email); err != nil {
+			return fmt.Errorf("parsing email %q: %w", p.Email, err)
+		}
+	}
+	if p.Timezone != "" {
+		if _, err := time.LoadLocation(p.Timezone); err != nil {
+			return fmt.Errorf("parsing timezone %q: %w", p.Timezone, err)
+		}
+	}
+	return nil
+}
+
+func AddPerson(ctx context.Context, q *save.Queries, p Person) error {
+	if p.Name == "" {
+		return fmt.Errorf("provide nonempty name")
+	}
+	if err := p.check(); err != nil {
+		return err
+	}
+	if err := q.AddPerson(ctx, save.AddPersonParams{
+		Name:        p.Name,
+		DisplayName: p.DisplayName,
+		Email:       p.Email,
+		Timezone:    p.Timezone,
+	}); err != nil {
+		return fmt.Errorf("adding %s: %w", p.Name, err)
+	}
+	return setidentities(ctx, q, p.Name, p.Identities)
+}
+
+// UpdatePerson replaces the details of someone already added.
+// Identities merge into the ones they already have, and an empty identity removes theirs at that destination.
+func UpdatePerson(ctx context.Context, q *save.Queries, p Person) error {
+	if _, err := GetPerson(ctx, q, p.Name); err != nil {
+		return err
+	}
+	if err := p.check(); err != nil {
+		return err
+	}
+	if err := q.UpdatePerson(ctx, save.UpdatePersonParams{
+		DisplayName: p.DisplayName,
+		Email:       p.Email,
+		Timezone:    p.Timezone,
+		Name:        p.Name,
+	}); err != nil {
+		return fmt.Errorf("updating %s: %w", p.Name, err)
+	}
+	return setidentities(ctx, q, p.Name, p.Identities)
+}
+
+func setidentities(ctx context.Context, q *save.Queries, person string, identities map[string]string) error {
+	var destinations []string
+	for d := range identities {
+		destinations = append(destinations, d)
+	}
+	sort.Strings(destinations)
+	for _, d := range destinations {
+		var err error
+		if identities[d] == "" {
+			err = q.RemoveIdentity(ctx, save.RemoveIdentityParams{Person: person, Destination: d})
+		} else {
+			err = q.SetIdentity(ctx, save.SetIdentityParams{Person: person, Destination: d, Name: identities[d]})
+		}
+		if err != nil {
+			return fmt.Errorf("setting identity of %s at %s: %w", person, d, err)
+		}
+	}
+	return nil
+}
+
+// known explains that nobody by that name was added with [AddPerson], or returns nil if somebody was.
+func known(ctx context.Context, q *save.Queries, name string) error {
+	_, err := q.GetPerson(ctx, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return unknown(name)
+	}
+	return err
+}
+
+func unknown(name string) error {
+	return fmt.Errorf("unknown person %q: add them first", name)
+}
+
+// GetPerson looks up someone added with [AddPerson], along with their identities.
+func GetPerson(ctx context.Context, q *save.Queries, name string) (Person, error) {
+	p, err := q.GetPerson(ctx, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Person{}, unknown(name)
+	}
+	if err != nil {
+		return Person{}, err
+	}
+	out := Person{
+		Name:        p.Name,
+		DisplayName: p.DisplayName,
+		Email:       p.Email,
+		Timezone:    p.Timezone,
+	}
+	identities, err := q.ListIdentities(ctx)
+	if err != nil {
+		return Person{}, err
+	}
+	for _, i := range identities {
+		if i.Person == name {
+			if out.Identities == nil {
+				out.Identities = make(map[string]string)
+			}
+			out.Identities[i.Destination] = i.Name
+		}
+	}
+	return out, nil
+}
+
+// People returns everyone in order of name.
+func People(ctx context.Context, q *save.Queries) ([]Person, error) {
+	people, err := q.ListPeople(ctx)
+	if err != nil {
+		return nil, err
+	}
+	identities, err := q.ListIdentities(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byperson := make(map[string]map[string]string)
+	for _, i := range identities {
+		if byperson[i.Person] == nil {
+			byperson[i.Person] = make(map[string]string)
+		}
+		byperson[i.Person][i.Destination] = i.Name
+	}
+	var out []Person
+	for _, p := range people {
+		out = append(out, Person{
+			Name:        p.Name,
+			DisplayName: p.DisplayName,
+			Email:       p.Email,
+			Timezone:    p.Timezone,
+			Identities:  byperson[p.Name],
+		})
+	}
+	return out, nil
+}
+
+// Identities maps each person with an identity at the destination to that identity.
+func Identities(ctx context.Context, q *save.Queries, destination string) (map[string]string, error) {
+	identities, err := q.ListIdentities(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]string)
+	for _, i := range identities {
+		if i.Destination == destination {
+			out[i.Person] = i.Name
+		}
+	}
+	return out, nil
+}
+
+// WritePeople writes people as a CSV, with their identities as destination=identity pairs separated by spaces.
+func WritePeople(w io.Writer, people []Person) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"name", "display_name", "email", "timezone", "identities"}); err != nil {
+		return err
+	}
+	for _, p := range people {
+		var identities []string
+		for d, i := range p.Identities {
+			identities = append(identities, d+"="+i)
+		}
+		sort.Strings(identities)
+		if err := out.Write([]string{p.Name, p.DisplayName, p.Email, p.Timezone, strings.Join(identities, " ")}); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,74 @@
+package cmd_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jreut/pager/v2/pkg/assert"
+	"github.com/jreut/pager/v2/pkg/cmd"
+	"github.com/jreut/pager/v2/pkg/save"
+	"github.com/jreut/pager/v2/pkg/save/savetest"
+)
+
+func TestPerson(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+
+	const schedule = "schedule"
+	assert.Nil(t, q.AddSchedule(ctx, schedule))
+
+	alice := cmd.Person{
+		Name:        "alice",
+		DisplayName: "Alice Anders",
+		Email:       "alice@example.com",
+		Timezone:    "Europe/Berlin",
+		Identities:  map[string]string{"opsgenie": "aanders", "pagerduty": "PABC123"},
+	}
+	assert.Nil(t, cmd.AddPerson(ctx, q, alice))
+	assert.Error(t, savetest.ErrUnique, cmd.AddPerson(ctx, q, cmd.Person{Name: "alice"}))
+	assert.Error(t, "provide nonempty name", cmd.AddPerson(ctx, q, cmd.Person{}))
+	assert.Error(t, `parsing email "bob"`, cmd.AddPerson(ctx, q, cmd.Person{Name: "bob", Email: "bob"}))
+	assert.Error(t, `parsing timezone "Nowhere/Special"`, cmd.AddPerson(ctx, q, cmd.Person{Name: "bob", Timezone: "Nowhere/Special"}))
+	assert.Nil(t, cmd.AddPerson(ctx, q, cmd.Person{Name: "bob"}))
+
+	got, err := cmd.GetPerson(ctx, q, "alice")
+	assert.Nil(t, err)
+	assert.Cmp(t, alice, got)
+
+	// Identities merge, and an empty one is removed.
+	alice.Email = "alice@example.org"
+	alice.Identities = map[string]string{"opsgenie": "alice", "pagerduty": ""}
+	assert.Nil(t, cmd.UpdatePerson(ctx, q, alice))
+	assert.Error(t, `unknown person "cindy"`, cmd.UpdatePerson(ctx, q, cmd.Person{Name: "cindy"}))
+
+	people, err := cmd.People(ctx, q)
+	assert.Nil(t, err)
+	assert.Cmp(t, []cmd.Person{
+		{
+			Name:        "alice",
+			DisplayName: "Alice Anders",
+			Email:       "alice@example.org",
+			Timezone:    "Europe/Berlin",
+			Identities:  map[string]string{"opsgenie": "alice"},
+		},
+		{Name: "bob"},
+	}, people)
+
+	identities, err := cmd.Identities(ctx, q, "opsgenie")
+	assert.Nil(t, err)
+	assert.Cmp(t, map[string]string{"alice": "alice"}, identities)
+
+	// Nobody else can join the schedule or take shifts.
+	at := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Error(t, `unknown person "alcie"`, cmd.EditSchedule(ctx, q, schedule, []cmd.Action{
+		{Who: "alcie", At: at, Kind: save.EventKindAdd},
+	}))
+	assert.Error(t, `unknown person "bbo"`, cmd.AddInterval(ctx, q, save.AddIntervalParams{
+		Person:    "bbo",
+		Schedule:  schedule,
+		StartAt:   at,
+		EndBefore: at.Add(time.Hour),
+		Kind:      save.IntervalKindShift,
+	}, false))
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/jreut/pager/v2/pkg/cost"
+	"github.com/jreut/pager/v2/pkg/save"
+)
+
+// prefer adjusts a tally by people's preferences during [a, b).
+//
+// A preference counts its weight times the cost of the time it overlaps against the tally.
+// So a weight of -1 counts like having already worked that time, and a small weight only breaks ties.
+func prefer(tally map[string]time.Duration, preferences []save.Interval, a, b time.Time, m cost.Model) map[string]time.Duration {
+	if len(preferences) == 0 {
+		return tally
+	}
+	out := make(map[string]time.Duration, len(tally))
+	for k, v := range tally {
+		out[k] = v
+	}
+	for _, p := range preferences {
+		start, end := p.StartAt, p.EndBefore
+		if start.Before(a) {
+			start = a
+		}
+		if end.After(b) {
+			end = b
+		}
+		if !start.Before(end) {
+			continue
+		}
+		out[p.Person] -= time.Duration(p.Weight * float64(m.Of(start, end)))
+	}
+	return out
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/jreut/pager/v2/pkg/interval"
+	"github.com/jreut/pager/v2/pkg/save"
+)
+
+// ProvenanceColumns tell which interval each one is, where it came from, who added it, why, and when.
+//
+// A shift that [interval.Flatten] merged from consecutive pieces for the same person shows where its first piece came from.
+var ProvenanceColumns = []interval.Column{
+	{
+		Name:  "id",
+		Value: func(i save.Interval) string { return strconv.FormatInt(i.ID, 10) },
+	},
+	{
+		Name:  "source",
+		Value: func(i save.Interval) string { return i.Source },
+	},
+	{
+		Name:  "author",
+		Value: func(i save.Interval) string { return i.Author },
+	},
+	{
+		Name:  "reason",
+		Value: func(i save.Interval) string { return i.Reason },
+	},
+	{
+		Name: "created_at",
+		Value: func(i save.Interval) string {
+			if i.CreatedAt.IsZero() {
+				return ""
+			}
+			return i.CreatedAt.Format(time.RFC3339)
+		},
+	},
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jreut/pager/v2/pkg/global"
+	"github.com/jreut/pager/v2/pkg/save"
+)
+
+type RemoveIntervalParams struct {
+	// ID picks the interval to remove.
+	ID int64
+	// Match picks the interval to remove when ID is zero.
+	// Every detail has to match exactly.
+	Match save.FindIntervalsParams
+	// Author records who removed the interval.
+	Author string
+}
+
+// RemoveInterval leaves a tombstone in place of an interval, so it stops counting but its history survives.
+//
+// Generate works around exclusions, and removing one doesn't undo that.
+// So removing an exclusion also returns the generated shifts that may have covered for it,
+// which stay as they are until the time is generated again.
+func RemoveInterval(ctx context.Context, q *save.Queries, arg RemoveIntervalParams) (removed save.Interval, stale []save.Interval, err error) {
+	removed, err = find(ctx, q, arg)
+	if err != nil {
+		return save.Interval{}, nil, err
+	}
+	if removed.RemovedAt.Valid {
+		return save.Interval{}, nil, fmt.Errorf("%s was already removed at %s", removed, removed.RemovedAt.Time.Format(time.RFC3339))
+	}
+	removed.RemovedAt = sql.NullTime{Time: global.Now(), Valid: true}
+	removed.RemovedBy = arg.Author
+	if err := q.RemoveInterval(ctx, save.RemoveIntervalParams{
+		RemovedAt: removed.RemovedAt,
+		RemovedBy: removed.RemovedBy,
+		ID:        removed.ID,
+	}); err != nil {
+		return save.Interval{}, nil, fmt.Errorf("removing %s: %w", removed, err)
+	}
+	if removed.Kind != save.IntervalKindExclusion {
+		return removed, nil, nil
+	}
+	// Shifts generated for someone else after the exclusion and during it could be covers because of it.
+	shifts, err := q.ListIntervals(ctx, save.ListIntervalsParams{
+		Schedule:  removed.Schedule,
+		Kind:      save.IntervalKindShift,
+		StartAt:   removed.StartAt,
+		EndBefore: removed.EndBefore,
+	})
+	if err != nil {
+		return save.Interval{}, nil, err
+	}
+	for _, i := range shifts {
+		if i.Generation.Valid && i.ID > removed.ID && i.Person != removed.Person {
+			stale = append(stale, i)
+		}
+	}
+	return removed, stale, nil
+}
+
+func find(ctx context.Context, q *save.Queries, arg RemoveIntervalParams) (save.Interval, error) {
+	if arg.ID != 0 {
+		i, err := q.GetInterval(ctx, arg.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return save.Interval{}, fmt.Errorf("no interval #%d", arg.ID)
+		}
+		return i, err
+	}
+	m := arg.Match
+	is, err := q.FindIntervals(ctx, m)
+	if err != nil {
+		return save.Interval{}, err
+	}
+	want := save.AddIntervalParams{
+		Person:    m.Person,
+		Schedule:  m.Schedule,
+		StartAt:   m.StartAt,
+		EndBefore: m.EndBefore,
+		Kind:      m.Kind,
+		Layer:     m.Layer,
+	}.Interval()
+	switch len(is) {
+	case 0:
+		return save.Interval{}, fmt.Errorf("no interval matches %s", want)
+	case 1:
+		return is[0], nil
+	default:
+		var ids []string
+		for _, i := range is {
+			ids = append(ids, fmt.Sprintf("#%d", i.ID))
+		}
+		return save.Interval{}, fmt.Errorf("%d intervals match %s: pick one of %s by ID", len(is), want, strings.Join(ids, ", "))
+	}
+}
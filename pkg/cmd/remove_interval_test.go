@@ -0,0 +1,105 @@
+package cmd_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jreut/pager/v2/pkg/assert"
+	"github.com/jreut/pager/v2/pkg/cmd"
+	"github.com/jreut/pager/v2/pkg/save"
+)
+
+func TestRemoveInterval(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+	addpeople(t, ctx, q, "alice", "bob")
+
+	const schedule = "schedule"
+	assert.Nil(t, q.AddSchedule(ctx, schedule))
+	day := func(n int) time.Time { return time.Date(2023, 1, 1+n, 0, 0, 0, 0, time.UTC) }
+	for _, i := range []save.AddIntervalParams{
+		{Person: "alice", StartAt: day(0), EndBefore: day(7)},
+		{Person: "bob", StartAt: day(1), EndBefore: day(2)},
+		{Person: "bob", StartAt: day(3), EndBefore: day(4)},
+		{Person: "bob", StartAt: day(3), EndBefore: day(4)},
+	} {
+		i.Schedule, i.Kind = schedule, save.IntervalKindShift
+		assert.Nil(t, cmd.AddInterval(ctx, q, i, true))
+	}
+	match := func(start, end time.Time) save.FindIntervalsParams {
+		return save.FindIntervalsParams{
+			Schedule:  schedule,
+			Person:    "bob",
+			Kind:      save.IntervalKindShift,
+			StartAt:   start,
+			EndBefore: end,
+		}
+	}
+
+	removed, stale, err := cmd.RemoveInterval(ctx, q, cmd.RemoveIntervalParams{Match: match(day(1), day(2)), Author: "carol"})
+	assert.Nil(t, err)
+	assert.Cmp(t, int64(2), removed.ID)
+	assert.Cmp(t, []save.Interval(nil), stale)
+
+	_, _, err = cmd.RemoveInterval(ctx, q, cmd.RemoveIntervalParams{Match: match(day(1), day(2))})
+	assert.Error(t, "no interval matches", err)
+	_, _, err = cmd.RemoveInterval(ctx, q, cmd.RemoveIntervalParams{Match: match(day(3), day(4))})
+	assert.Error(t, "2 intervals match .*: pick one of #3, #4 by ID", err)
+	_, _, err = cmd.RemoveInterval(ctx, q, cmd.RemoveIntervalParams{ID: 3})
+	assert.Nil(t, err)
+	_, _, err = cmd.RemoveInterval(ctx, q, cmd.RemoveIntervalParams{ID: 3})
+	assert.Error(t, "#3 was already removed", err)
+	_, _, err = cmd.RemoveInterval(ctx, q, cmd.RemoveIntervalParams{ID: 99})
+	assert.Error(t, "no interval #99", err)
+
+	// The tombstone keeps the interval on record.
+	got, err := q.GetInterval(ctx, 2)
+	assert.Nil(t, err)
+	assert.Cmp(t, "carol", got.RemovedBy)
+	assert.Cmp(t, true, got.RemovedAt.Valid)
+
+	shifts, err := cmd.ShowSchedule(ctx, q, schedule, day(0), day(7))
+	assert.Nil(t, err)
+	var people []string
+	for _, s := range shifts {
+		people = append(people, s.Person)
+	}
+	assert.Cmp(t, []string{"alice", "bob", "alice"}, people)
+}
+
+func TestRemoveIntervalExclusion(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+	addpeople(t, ctx, q, "alice", "bob")
+
+	const schedule = "schedule"
+	assert.Nil(t, q.AddSchedule(ctx, schedule))
+	day := func(n int) time.Time { return time.Date(2023, 1, 1+n, 0, 0, 0, 0, time.UTC) }
+	assert.Nil(t, cmd.EditSchedule(ctx, q, schedule, []cmd.Action{
+		{Kind: save.EventKindAdd, Who: "alice", At: day(0)},
+		{Kind: save.EventKindAdd, Who: "bob", At: day(0)},
+	}))
+	assert.Nil(t, cmd.AddInterval(ctx, q, save.AddIntervalParams{
+		Person:    "alice",
+		Schedule:  schedule,
+		StartAt:   day(1),
+		EndBefore: day(2),
+		Kind:      save.IntervalKindExclusion,
+	}, false))
+	assert.Nil(t, cmd.Generate(ctx, q, cmd.GenerateParams{
+		Schedule:    schedule,
+		Style:       cmd.StyleWeekly,
+		StyleParams: cmd.StyleParams{"day": "Sunday"},
+		StartAt:     day(0),
+		EndBefore:   day(7),
+	}))
+
+	_, stale, err := cmd.RemoveInterval(ctx, q, cmd.RemoveIntervalParams{ID: 1})
+	assert.Nil(t, err)
+	if len(stale) != 1 {
+		t.Fatalf("want 1 stale cover, got %v", stale)
+	}
+	assert.Cmp(t, "bob", stale[0].Person)
+	assert.Cmp(t, day(1), stale[0].StartAt)
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jreut/pager/v2/pkg/cost"
+	"github.com/jreut/pager/v2/pkg/save"
+)
+
+// Total sums up one person's shifts.
+type Total struct {
+	Person string
+	Shifts int
+	// Hours is the raw time on call.
+	Hours time.Duration
+	// Weighted is the time on call according to a [cost.Model].
+	Weighted time.Duration
+}
+
+// Report totals each person's shifts in [start, end), heaviest first.
+// Like [Generate], it adds the schedule's holidays to m.Holidays.
+func Report(ctx context.Context, q *save.Queries, schedule string, start, end time.Time, m cost.Model) ([]Total, error) {
+	shifts, err := ShowSchedule(ctx, q, schedule, start, end)
+	if err != nil {
+		return nil, err
+	}
+	holidays, err := Holidays(ctx, q, schedule, start, end)
+	if err != nil {
+		return nil, err
+	}
+	m.Holidays = append(periods(holidays), m.Holidays...)
+	totals := make(map[string]*Total)
+	for _, s := range shifts {
+		t, ok := totals[s.Person]
+		if !ok {
+			t = &Total{Person: s.Person}
+			totals[s.Person] = t
+		}
+		t.Shifts++
+		t.Hours += s.EndBefore.Sub(s.StartAt)
+		t.Weighted += m.Of(s.StartAt, s.EndBefore)
+	}
+	var out []Total
+	for _, t := range totals {
+		out = append(out, *t)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Weighted == out[j].Weighted {
+			return out[i].Person < out[j].Person
+		}
+		return out[i].Weighted > out[j].Weighted
+	})
+	return out, nil
+}
+
+func WriteReportCSV(w io.Writer, totals []Total) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"person", "shifts", "hours", "weighted_hours"}); err != nil {
+		return err
+	}
+	for _, t := range totals {
+		if err := out.Write([]string{
+			t.Person,
+			strconv.Itoa(t.Shifts),
+			strconv.FormatFloat(t.Hours.Hours(), 'f', -1, 64),
+			strconv.FormatFloat(t.Weighted.Hours(), 'f', -1, 64),
+		}); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,58 @@
+package cmd_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jreut/pager/v2/pkg/assert"
+	"github.com/jreut/pager/v2/pkg/cmd"
+	"github.com/jreut/pager/v2/pkg/cost"
+	"github.com/jreut/pager/v2/pkg/save"
+)
+
+func TestReport(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+	addpeople(t, ctx, q, "alice", "bob")
+
+	const schedule = "schedule"
+	assert.Nil(t, q.AddSchedule(ctx, schedule))
+	// 6 January 2023 was a Friday.
+	friday := time.Date(2023, 1, 6, 0, 0, 0, 0, time.UTC)
+	for _, i := range []save.AddIntervalParams{
+		{Person: "alice", StartAt: friday, EndBefore: friday.AddDate(0, 0, 1)},
+		{Person: "bob", StartAt: friday.AddDate(0, 0, 1), EndBefore: friday.AddDate(0, 0, 3)},
+		{Person: "alice", StartAt: friday.AddDate(0, 0, 3), EndBefore: friday.AddDate(0, 0, 4)},
+	} {
+		i.Schedule = schedule
+		i.Kind = save.IntervalKindShift
+		assert.Nil(t, q.AddInterval(ctx, i))
+	}
+
+	m, err := cost.Parse("saturday=2,sunday=2")
+	assert.Nil(t, err)
+	got, err := cmd.Report(ctx, q, schedule, friday, friday.AddDate(0, 0, 7), m)
+	assert.Nil(t, err)
+	assert.Cmp(t, []cmd.Total{
+		{Person: "bob", Shifts: 1, Hours: 48 * time.Hour, Weighted: 96 * time.Hour},
+		{Person: "alice", Shifts: 2, Hours: 48 * time.Hour, Weighted: 48 * time.Hour},
+	}, got)
+	assert.Nil(t, cmd.WriteReportCSV(assert.Golden(t, "report.csv"), got))
+
+	// The schedule's holidays weigh its shifts too.
+	assert.Nil(t, q.AddHoliday(ctx, save.AddHolidayParams{
+		Schedule:  schedule,
+		Name:      "Epiphany",
+		StartAt:   friday,
+		EndBefore: friday.AddDate(0, 0, 1),
+	}))
+	m, err = cost.Parse("holiday=3")
+	assert.Nil(t, err)
+	got, err = cmd.Report(ctx, q, schedule, friday, friday.AddDate(0, 0, 7), m)
+	assert.Nil(t, err)
+	assert.Cmp(t, []cmd.Total{
+		{Person: "alice", Shifts: 2, Hours: 48 * time.Hour, Weighted: 96 * time.Hour},
+		{Person: "bob", Shifts: 1, Hours: 48 * time.Hour, Weighted: 48 * time.Hour},
+	}, got)
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/jreut/pager/v2/pkg/save"
+)
+
+// A Requirement bounds how many people with a tag are on call at once, across all layers.
+//
+// "Every shift includes someone senior" is AtLeast 1 of "senior".
+// "Never pair two juniors" is AtMost 1 of "junior".
+type Requirement struct {
+	Tag     string
+	AtLeast int
+	// AtMost is negative for no limit.
+	AtMost int
+}
+
+// SetRequirement replaces the schedule's requirement for the tag.
+// A requirement without any limits removes it.
+func SetRequirement(ctx context.Context, q *save.Queries, schedule string, r Requirement) error {
+	if r.Tag == "" {
+		return fmt.Errorf("provide nonempty tag")
+	}
+	if r.AtLeast < 0 {
+		return fmt.Errorf("at least %d must not be negative", r.AtLeast)
+	}
+	if r.AtMost < 0 {
+		r.AtMost = -1
+	}
+	if r.AtMost >= 0 && r.AtMost < r.AtLeast {
+		return fmt.Errorf("at most %d is fewer than at least %d", r.AtMost, r.AtLeast)
+	}
+	if r.AtLeast == 0 && r.AtMost < 0 {
+		return q.RemoveRequirement(ctx, save.RemoveRequirementParams{
+			Schedule: schedule,
+			Tag:      r.Tag,
+		})
+	}
+	return q.SetRequirement(ctx, save.SetRequirementParams{
+		Schedule: schedule,
+		Tag:      r.Tag,
+		AtLeast:  int64(r.AtLeast),
+		AtMost:   int64(r.AtMost),
+	})
+}
+
+// Requirements returns the schedule's requirements in order of tag.
+func Requirements(ctx context.Context, q *save.Queries, schedule string) ([]Requirement, error) {
+	rs, err := q.ListRequirements(ctx, schedule)
+	if err != nil {
+		return nil, err
+	}
+	var out []Requirement
+	for _, r := range rs {
+		out = append(out, Requirement{
+			Tag:     r.Tag,
+			AtLeast: int(r.AtLeast),
+			AtMost:  int(r.AtMost),
+		})
+	}
+	return out, nil
+}
+
+// meets explains which requirement the team breaks, or returns nil if it breaks none.
+// Open counts the places on the team still to fill, which could yet make up for too few people with a tag.
+func meets(requirements []Requirement, tags map[string]map[string]bool, team []string, open int) error {
+	for _, r := range requirements {
+		seen := make(map[string]bool)
+		var n int
+		for _, p := range team {
+			if tags[p][r.Tag] && !seen[p] {
+				seen[p] = true
+				n++
+			}
+		}
+		if r.AtMost >= 0 && n > r.AtMost {
+			return fmt.Errorf("%d people tagged %q (max %d)", n, r.Tag, r.AtMost)
+		}
+		if n+open < r.AtLeast {
+			return fmt.Errorf("%d people tagged %q (min %d)", n, r.Tag, r.AtLeast)
+		}
+	}
+	return nil
+}
+
+// A Violation is a time when the people on call break one of the schedule's requirements.
+type Violation struct {
+	StartAt   time.Time
+	EndBefore time.Time
+	Problem   string
+}
+
+// Validate checks who is on call across all layers during [start, end) against the schedule's requirements.
+// Times when nobody is on call are not shifts, so they never break a requirement.
+func Validate(ctx context.Context, q *save.Queries, schedule string, start, end time.Time) ([]Violation, error) {
+	requirements, err := Requirements(ctx, q, schedule)
+	if err != nil {
+		return nil, err
+	}
+	if len(requirements) == 0 {
+		return nil, nil
+	}
+	tags, err := Tags(ctx, q, schedule)
+	if err != nil {
+		return nil, err
+	}
+	shifts, err := ShowLayers(ctx, q, schedule, start, end)
+	if err != nil {
+		return nil, err
+	}
+	// Whoever is on call only changes at the boundaries of shifts.
+	var bounds []time.Time
+	for _, s := range shifts {
+		bounds = append(bounds, s.StartAt, s.EndBefore)
+	}
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i].Before(bounds[j]) })
+	var out []Violation
+	for i := 0; i+1 < len(bounds); i++ {
+		a, b := bounds[i], bounds[i+1]
+		if !a.Before(b) {
+			continue
+		}
+		var team []string
+		for _, s := range shifts {
+			if !s.StartAt.After(a) && !s.EndBefore.Before(b) {
+				team = append(team, s.Person)
+			}
+		}
+		if len(team) == 0 {
+			continue
+		}
+		// Only violations before n come from earlier segments.
+		n := len(out)
+		for _, r := range requirements {
+			err := meets([]Requirement{r}, tags, team, 0)
+			if err == nil {
+				continue
+			}
+			problem := err.Error()
+			// Merge with the same problem right before this one.
+			merged := false
+			for j := n - 1; j >= 0; j-- {
+				if out[j].EndBefore.Equal(a) && out[j].Problem == problem {
+					out[j].EndBefore = b
+					merged = true
+					break
+				}
+			}
+			if !merged {
+				out = append(out, Violation{StartAt: a, EndBefore: b, Problem: problem})
+			}
+		}
+	}
+	return out, nil
+}
+
+// WriteViolations prints one violation per line.
+func WriteViolations(w io.Writer, violations []Violation) error {
+	for _, v := range violations {
+		if _, err := fmt.Fprintf(w, "[%s, %s) %s\n", v.StartAt.Format(time.RFC3339), v.EndBefore.Format(time.RFC3339), v.Problem); err != nil {
+			return err
+		}
+	}
+	return nil
+}
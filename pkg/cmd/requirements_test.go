@@ -0,0 +1,60 @@
+package cmd_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jreut/pager/v2/pkg/assert"
+	"github.com/jreut/pager/v2/pkg/cmd"
+	"github.com/jreut/pager/v2/pkg/save"
+)
+
+func TestValidate(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+	addpeople(t, ctx, q, "alice", "bob", "cindy", "dan", "eve")
+
+	const schedule = "schedule"
+	assert.Nil(t, q.AddSchedule(ctx, schedule))
+	assert.Nil(t, cmd.AddLayer(ctx, q, schedule, "secondary"))
+	assert.Nil(t, cmd.EditSchedule(ctx, q, schedule, []cmd.Action{
+		{Who: "alice", Kind: cmd.ActionTag, Tag: "senior"},
+		{Who: "cindy", Kind: cmd.ActionTag, Tag: "junior"},
+		{Who: "dan", Kind: cmd.ActionTag, Tag: "junior"},
+	}))
+	day := func(n int) time.Time { return time.Date(2023, 1, 1+n, 0, 0, 0, 0, time.UTC) }
+	for _, i := range []save.AddIntervalParams{
+		{Person: "alice", StartAt: day(0), EndBefore: day(2)},
+		{Person: "bob", StartAt: day(2), EndBefore: day(4)},
+		{Person: "cindy", StartAt: day(4), EndBefore: day(6)},
+		{Person: "eve", StartAt: day(0), EndBefore: day(3), Layer: "secondary"},
+		{Person: "dan", StartAt: day(3), EndBefore: day(6), Layer: "secondary"},
+	} {
+		i.Schedule, i.Kind = schedule, save.IntervalKindShift
+		assert.Nil(t, cmd.AddInterval(ctx, q, i, false))
+	}
+
+	// Without requirements, anything goes.
+	got, err := cmd.Validate(ctx, q, schedule, day(0), day(7))
+	assert.Nil(t, err)
+	assert.Cmp(t, []cmd.Violation(nil), got)
+
+	assert.Nil(t, cmd.SetRequirement(ctx, q, schedule, cmd.Requirement{Tag: "senior", AtLeast: 1, AtMost: -1}))
+	assert.Nil(t, cmd.SetRequirement(ctx, q, schedule, cmd.Requirement{Tag: "junior", AtMost: 1}))
+	assert.Error(t, "fewer than at least", cmd.SetRequirement(ctx, q, schedule, cmd.Requirement{Tag: "junior", AtLeast: 2, AtMost: 1}))
+	got, err = cmd.Validate(ctx, q, schedule, day(0), day(7))
+	assert.Nil(t, err)
+	assert.Cmp(t, []cmd.Violation{
+		{StartAt: day(2), EndBefore: day(6), Problem: `0 people tagged "senior" (min 1)`},
+		{StartAt: day(4), EndBefore: day(6), Problem: `2 people tagged "junior" (max 1)`},
+	}, got)
+
+	// Removing a requirement forgets its violations.
+	assert.Nil(t, cmd.SetRequirement(ctx, q, schedule, cmd.Requirement{Tag: "senior", AtMost: -1}))
+	got, err = cmd.Validate(ctx, q, schedule, day(0), day(7))
+	assert.Nil(t, err)
+	assert.Cmp(t, []cmd.Violation{
+		{StartAt: day(4), EndBefore: day(6), Problem: `2 people tagged "junior" (max 1)`},
+	}, got)
+}
@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/jreut/pager/v2/pkg/global"
+	"github.com/jreut/pager/v2/pkg/save"
+)
+
+// A ScheduleSummary counts what is in a schedule.
+type ScheduleSummary struct {
+	Name        string
+	Description string
+	// People counts who is in the schedule, or will be: everyone whose last event doesn't remove them.
+	People int
+	// Intervals counts the intervals that haven't been removed, of every kind.
+	Intervals int
+	// ArchivedAt is zero unless the schedule is archived.
+	ArchivedAt time.Time
+}
+
+// ListSchedules summarizes the schedules in order of name, leaving out archived ones unless all is set.
+func ListSchedules(ctx context.Context, q *save.Queries, all bool) ([]ScheduleSummary, error) {
+	schedules, err := q.ListSchedules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []ScheduleSummary
+	for _, s := range schedules {
+		if s.ArchivedAt.Valid && !all {
+			continue
+		}
+		settings, err := GetSettings(ctx, q, s.Name)
+		if err != nil {
+			return nil, err
+		}
+		events, err := q.ListEvents(ctx, s.Name)
+		if err != nil {
+			return nil, err
+		}
+		in := make(map[string]bool)
+		for _, e := range events {
+			in[e.Person] = e.Kind != save.EventKindRemove
+		}
+		var people int
+		for _, ok := range in {
+			if ok {
+				people++
+			}
+		}
+		intervals, err := q.CountIntervals(ctx, s.Name)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, ScheduleSummary{
+			Name:        s.Name,
+			Description: settings.Description,
+			People:      people,
+			Intervals:   int(intervals),
+			ArchivedAt:  s.ArchivedAt.Time,
+		})
+	}
+	return out, nil
+}
+
+// WriteSchedules writes the summaries as a CSV, leaving archived_at empty for schedules in use.
+func WriteSchedules(w io.Writer, schedules []ScheduleSummary) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"schedule", "description", "people", "intervals", "archived_at"}); err != nil {
+		return err
+	}
+	for _, s := range schedules {
+		var archived string
+		if !s.ArchivedAt.IsZero() {
+			archived = s.ArchivedAt.Format(time.RFC3339)
+		}
+		if err := out.Write([]string{s.Name, s.Description, strconv.Itoa(s.People), strconv.Itoa(s.Intervals), archived}); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// unused explains that a schedule by that name was already added, or returns nil if none was.
+func unused(ctx context.Context, q *save.Queries, name string) error {
+	if name == "" {
+		return fmt.Errorf("provide nonempty name")
+	}
+	_, err := q.GetSchedule(ctx, name)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+	case err != nil:
+		return err
+	default:
+		return fmt.Errorf("schedule %q already exists", name)
+	}
+}
+
+// RenameSchedule gives the schedule and everything in it a new name.
+func RenameSchedule(ctx context.Context, q *save.Queries, from, to string) error {
+	if err := knownSchedule(ctx, q, from); err != nil {
+		return err
+	}
+	if err := unused(ctx, q, to); err != nil {
+		return err
+	}
+	if err := q.RenameSchedule(ctx, from, to); err != nil {
+		return fmt.Errorf("renaming %s to %s: %w", from, to, err)
+	}
+	return nil
+}
+
+// ArchiveSchedule hides the schedule from [ListSchedules], or brings it back if restore is set.
+func ArchiveSchedule(ctx context.Context, q *save.Queries, name string, restore bool) error {
+	s, err := getSchedule(ctx, q, name)
+	if err != nil {
+		return err
+	}
+	var at sql.NullTime
+	switch {
+	case restore && !s.ArchivedAt.Valid:
+		return fmt.Errorf("schedule %q isn't archived", name)
+	case !restore && s.ArchivedAt.Valid:
+		return fmt.Errorf("schedule %q was already archived at %s", name, s.ArchivedAt.Time.Format(time.RFC3339))
+	case !restore:
+		at = sql.NullTime{Time: global.Now(), Valid: true}
+	}
+	return q.ArchiveSchedule(ctx, save.ArchiveScheduleParams{ArchivedAt: at, Name: name})
+}
+
+// DeleteSchedule deletes an archived schedule and everything in it.
+// Archiving it first guards against deleting a schedule in use, and `undo` brings it back.
+func DeleteSchedule(ctx context.Context, q *save.Queries, name string) error {
+	s, err := getSchedule(ctx, q, name)
+	if err != nil {
+		return err
+	}
+	if !s.ArchivedAt.Valid {
+		return fmt.Errorf("schedule %q isn't archived: archive it first", name)
+	}
+	if err := q.DeleteSchedule(ctx, name); err != nil {
+		return fmt.Errorf("deleting %s: %w", name, err)
+	}
+	return nil
+}
+
+// CloneSchedule copies the schedule into a new one to try changes out on, like a reorg.
+// The clone gets the schedule's people, tags, layers, holidays, constraints, requirements and settings,
+// along with the intervals that end after since.
+// It gets no remotes, so that `apply` can't write the clone over the real schedule by mistake.
+func CloneSchedule(ctx context.Context, q *save.Queries, from, to string, since time.Time) error {
+	settings, err := GetSettings(ctx, q, from)
+	if err != nil {
+		return err
+	}
+	if err := unused(ctx, q, to); err != nil {
+		return err
+	}
+	if err := q.AddSchedule(ctx, to); err != nil {
+		return err
+	}
+	settings.Schedule, settings.Remotes = to, nil
+	if err := SetSettings(ctx, q, settings); err != nil {
+		return err
+	}
+	c, err := GetConstraints(ctx, q, from)
+	if err != nil {
+		return err
+	}
+	if !c.zero() {
+		if err := SetConstraints(ctx, q, to, c); err != nil {
+			return err
+		}
+	}
+	requirements, err := q.ListRequirements(ctx, from)
+	if err != nil {
+		return err
+	}
+	for _, r := range requirements {
+		if err := q.SetRequirement(ctx, save.SetRequirementParams{Schedule: to, Tag: r.Tag, AtLeast: r.AtLeast, AtMost: r.AtMost}); err != nil {
+			return err
+		}
+	}
+	layers, err := q.ListLayers(ctx, from)
+	if err != nil {
+		return err
+	}
+	for _, l := range layers {
+		if err := q.AddLayer(ctx, save.AddLayerParams{Schedule: to, Name: l}); err != nil {
+			return err
+		}
+	}
+	holidays, err := q.ListHolidays(ctx, from)
+	if err != nil {
+		return err
+	}
+	for _, h := range holidays {
+		if err := q.AddHoliday(ctx, save.AddHolidayParams{Schedule: to, Name: h.Name, StartAt: h.StartAt, EndBefore: h.EndBefore}); err != nil {
+			return err
+		}
+	}
+	events, err := q.ListEvents(ctx, from)
+	if err != nil {
+		return err
+	}
+	// Shadows go last, in the order they were added, since the last one for each person wins.
+	shadows, err := q.ListShadows(ctx, from)
+	if err != nil {
+		return err
+	}
+	for _, e := range events {
+		if e.Kind == save.EventKindShadow {
+			continue
+		}
+		if err := q.AddEvent(ctx, save.AddEventParams{Person: e.Person, Schedule: to, Kind: e.Kind, At: e.At}); err != nil {
+			return err
+		}
+	}
+	for _, e := range shadows {
+		if err := q.AddEvent(ctx, save.AddEventParams{Person: e.Person, Schedule: to, Kind: e.Kind, At: e.At}); err != nil {
+			return err
+		}
+	}
+	tags, err := q.ListTags(ctx, from)
+	if err != nil {
+		return err
+	}
+	for _, t := range tags {
+		if err := q.AddTag(ctx, save.AddTagParams{Person: t.Person, Schedule: to, Tag: t.Tag}); err != nil {
+			return err
+		}
+	}
+	// Generated intervals keep coming from a generation of their own schedule,
+	// so that generating the clone again replaces them.
+	generations, err := q.ListGenerations(ctx, from)
+	if err != nil {
+		return err
+	}
+	cloned := make(map[int64]int64)
+	for _, g := range generations {
+		id, err := q.AddGeneration(ctx, save.AddGenerationParams{
+			Schedule:  to,
+			Style:     g.Style,
+			Params:    g.Params,
+			StartAt:   g.StartAt,
+			EndBefore: g.EndBefore,
+			Seed:      g.Seed,
+		})
+		if err != nil {
+			return err
+		}
+		cloned[g.ID] = id
+	}
+	intervals, err := q.ListIntervalsEndingAfter(ctx, save.ListIntervalsEndingAfterParams{Schedule: from, EndBefore: since})
+	if err != nil {
+		return err
+	}
+	for _, i := range intervals {
+		arg := i.Params()
+		arg.Schedule = to
+		if arg.Generation.Valid {
+			arg.Generation.Int64 = cloned[arg.Generation.Int64]
+		}
+		if err := q.AddInterval(ctx, arg); err != nil {
+			return fmt.Errorf("cloning %s: %w", i, err)
+		}
+	}
+	return nil
+}
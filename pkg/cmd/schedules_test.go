@@ -0,0 +1,155 @@
+package cmd_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jreut/pager/v2/pkg/assert"
+	"github.com/jreut/pager/v2/pkg/cmd"
+	"github.com/jreut/pager/v2/pkg/save"
+)
+
+func TestScheduleLifecycle(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+	start := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	assert.Nil(t, q.AddSchedule(ctx, "a"))
+	assert.Nil(t, q.AddSchedule(ctx, "b"))
+	addpeople(t, ctx, q, "alice", "bob")
+	assert.Nil(t, cmd.EditSchedule(ctx, q, "a", []cmd.Action{
+		{Kind: save.EventKindAdd, Who: "alice", At: start},
+		{Kind: save.EventKindAdd, Who: "bob", At: start},
+		{Kind: save.EventKindRemove, Who: "bob", At: start.AddDate(0, 0, 7)},
+	}))
+	assert.Nil(t, cmd.SetSettings(ctx, q, cmd.Settings{Schedule: "a", Description: "on call", Remotes: map[string]string{"opsgenie": "abc-123"}}))
+	assert.Nil(t, cmd.AddInterval(ctx, q, save.AddIntervalParams{
+		Person:    "alice",
+		Schedule:  "a",
+		StartAt:   start,
+		EndBefore: start.AddDate(0, 0, 7),
+		Kind:      save.IntervalKindShift,
+	}, false))
+
+	list := func(all bool) []cmd.ScheduleSummary {
+		t.Helper()
+		got, err := cmd.ListSchedules(ctx, q, all)
+		assert.Nil(t, err)
+		return got
+	}
+	assert.Cmp(t, []cmd.ScheduleSummary{
+		{Name: "a", Description: "on call", People: 1, Intervals: 1},
+		{Name: "b"},
+	}, list(false))
+
+	assert.Error(t, `schedule "b" already exists`, cmd.RenameSchedule(ctx, q, "a", "b"))
+	assert.Error(t, `unknown schedule "c": add it first`, cmd.RenameSchedule(ctx, q, "c", "d"))
+	assert.Nil(t, cmd.RenameSchedule(ctx, q, "a", "c"))
+	assert.Cmp(t, []cmd.ScheduleSummary{
+		{Name: "b"},
+		{Name: "c", Description: "on call", People: 1, Intervals: 1},
+	}, list(false))
+	remote, err := cmd.RemoteSchedule(ctx, q, "c", "opsgenie")
+	assert.Nil(t, err)
+	assert.Cmp(t, "abc-123", remote)
+
+	assert.Error(t, `schedule "b" isn't archived: archive it first`, cmd.DeleteSchedule(ctx, q, "b"))
+	assert.Error(t, `schedule "b" isn't archived`, cmd.ArchiveSchedule(ctx, q, "b", true))
+	assert.Nil(t, cmd.ArchiveSchedule(ctx, q, "b", false))
+	assert.Error(t, `schedule "b" was already archived at 2000-01-01T00:00:00Z`, cmd.ArchiveSchedule(ctx, q, "b", false))
+	assert.Cmp(t, []cmd.ScheduleSummary{
+		{Name: "c", Description: "on call", People: 1, Intervals: 1},
+	}, list(false))
+	assert.Cmp(t, []cmd.ScheduleSummary{
+		{Name: "b", ArchivedAt: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Name: "c", Description: "on call", People: 1, Intervals: 1},
+	}, list(true))
+	assert.Nil(t, cmd.ArchiveSchedule(ctx, q, "b", true))
+	assert.Cmp(t, 2, len(list(false)))
+
+	assert.Nil(t, cmd.ArchiveSchedule(ctx, q, "c", false))
+	assert.Nil(t, cmd.DeleteSchedule(ctx, q, "c"))
+	assert.Cmp(t, []cmd.ScheduleSummary{{Name: "b"}}, list(true))
+	_, err = q.GetSchedule(ctx, "c")
+	assert.Error(t, "sql: no rows in result set", err)
+}
+
+func TestCloneSchedule(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+	const schedule = "schedule"
+	start := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	assert.Nil(t, q.AddSchedule(ctx, schedule))
+	addpeople(t, ctx, q, "alice", "bob")
+	assert.Nil(t, cmd.EditSchedule(ctx, q, schedule, []cmd.Action{
+		{Kind: save.EventKindAdd, Who: "alice", At: start},
+		{Kind: save.EventKindAdd, Who: "bob", At: start},
+	}))
+	settings := cmd.Settings{
+		Schedule:    schedule,
+		Description: "on call",
+		Style:       "Weekly",
+		StyleParams: cmd.StyleParams{"day": "Monday"},
+		Remotes:     map[string]string{"opsgenie": "abc-123"},
+	}
+	assert.Nil(t, cmd.SetSettings(ctx, q, settings))
+	constraints := cmd.Constraints{MaxConsecutive: 2}
+	assert.Nil(t, cmd.SetConstraints(ctx, q, schedule, constraints))
+	assert.Nil(t, cmd.AddLayer(ctx, q, schedule, "secondary"))
+	assert.Nil(t, cmd.Generate(ctx, q, cmd.GenerateParams{
+		Schedule:  schedule,
+		StartAt:   start,
+		EndBefore: start.AddDate(0, 0, 21),
+	}))
+
+	assert.Error(t, `unknown schedule "unknown": add it first`, cmd.CloneSchedule(ctx, q, "unknown", "clone", start))
+	assert.Error(t, `schedule "schedule" already exists`, cmd.CloneSchedule(ctx, q, schedule, schedule, start))
+	since := start.AddDate(0, 0, 7)
+	assert.Nil(t, cmd.CloneSchedule(ctx, q, schedule, "clone", since))
+
+	got, err := cmd.GetSettings(ctx, q, "clone")
+	assert.Nil(t, err)
+	settings.Schedule, settings.Remotes = "clone", nil
+	assert.Cmp(t, settings, got)
+	gotconstraints, err := cmd.GetConstraints(ctx, q, "clone")
+	assert.Nil(t, err)
+	assert.Cmp(t, constraints, gotconstraints)
+	layers, err := cmd.Layers(ctx, q, "clone")
+	assert.Nil(t, err)
+	assert.Cmp(t, []string{save.LayerPrimary, "secondary"}, layers)
+
+	// Only the intervals ending after since come over, from a generation of the clone's own.
+	original, err := cmd.ShowSchedule(ctx, q, schedule, start, start.AddDate(0, 0, 21))
+	assert.Nil(t, err)
+	cloned, err := cmd.ShowSchedule(ctx, q, "clone", start, start.AddDate(0, 0, 21))
+	assert.Nil(t, err)
+	assert.Cmp(t, 3, len(original))
+	assert.Cmp(t, 2, len(cloned))
+	generations, err := q.ListGenerations(ctx, "clone")
+	assert.Nil(t, err)
+	assert.Cmp(t, 1, len(generations))
+	for i, c := range cloned {
+		o := original[i+1]
+		assert.Cmp(t, o.Person, c.Person)
+		assert.Cmp(t, o.StartAt, c.StartAt)
+		assert.Cmp(t, o.EndBefore, c.EndBefore)
+		assert.Cmp(t, generations[0].ID, c.Generation.Int64)
+	}
+
+	// Generating the clone again replaces what it was given.
+	assert.Nil(t, cmd.Generate(ctx, q, cmd.GenerateParams{
+		Schedule:  "clone",
+		StartAt:   since,
+		EndBefore: start.AddDate(0, 0, 21),
+	}))
+	cloned, err = cmd.ShowSchedule(ctx, q, "clone", start, start.AddDate(0, 0, 21))
+	assert.Nil(t, err)
+	assert.Cmp(t, 2, len(cloned))
+
+	// The original is untouched.
+	got, err = cmd.GetSettings(ctx, q, schedule)
+	assert.Nil(t, err)
+	assert.Cmp(t, "abc-123", got.Remotes["opsgenie"])
+}
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jreut/pager/v2/pkg/save"
+)
+
+// Settings are a schedule's defaults, so that commands taking the schedule don't need every option repeated.
+type Settings struct {
+	Schedule string
+	// Timezone names where the schedule's times are shown, like Europe/Berlin.
+	// It only changes how times are shown, and never when shifts hand off: that is the style's own tz parameter.
+	// Empty leaves times as they were added.
+	Timezone    string
+	Description string
+	// Style and StyleParams are what [Generate] uses unless it is given a style.
+	Style       string
+	StyleParams StyleParams
+	// Remotes maps each destination, like "opsgenie", to the schedule's ID or name there.
+	// Destinations without one use the schedule's name.
+	Remotes map[string]string
+}
+
+func (s Settings) check() error {
+	if s.Timezone != "" {
+		if _, err := time.LoadLocation(s.Timezone); err != nil {
+			return fmt.Errorf("parsing timezone %q: %w", s.Timezone, err)
+		}
+	}
+	if s.Style == "" {
+		if len(s.StyleParams) > 0 {
+			return fmt.Errorf("provide a style for the parameters %s", s.StyleParams)
+		}
+		return nil
+	}
+	// Check what Generate would get when given no style.
+	style, params, err := s.style("", nil)
+	if err != nil {
+		return err
+	}
+	_, err = NewRotation(style, params)
+	return err
+}
+
+// Location is where the schedule's times are shown.
+func (s Settings) Location() (*time.Location, error) {
+	return time.LoadLocation(s.Timezone)
+}
+
+// RemoteSchedule is the schedule's ID or name at the destination: its remote there if it has one, or else its name.
+// The schedule needn't have been added, since `apply` writes whatever it reads.
+func RemoteSchedule(ctx context.Context, q *save.Queries, schedule, destination string) (string, error) {
+	remotes, err := q.ListRemoteSchedules(ctx, schedule)
+	if err != nil {
+		return "", err
+	}
+	for _, r := range remotes {
+		if r.Destination == destination {
+			return r.Name, nil
+		}
+	}
+	return schedule, nil
+}
+
+// style fills in the style and parameters that [Generate] wasn't given.
+// The stored parameters only go with the stored style, and nil params means none were given.
+func (s Settings) style(style string, params StyleParams) (string, StyleParams, error) {
+	if style == "" {
+		style = s.Style
+	}
+	if style == "" {
+		return "", nil, fmt.Errorf("provide a style, or set one for schedule %q", s.Schedule)
+	}
+	if params == nil && style == s.Style {
+		params = s.StyleParams
+	}
+	return style, params, nil
+}
+
+// knownSchedule explains that no schedule by that name was added, or returns nil if one was.
+func knownSchedule(ctx context.Context, q *save.Queries, name string) error {
+	_, err := getSchedule(ctx, q, name)
+	return err
+}
+
+func getSchedule(ctx context.Context, q *save.Queries, name string) (save.Schedule, error) {
+	s, err := q.GetSchedule(ctx, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return save.Schedule{}, fmt.Errorf("unknown schedule %q: add it first", name)
+	}
+	return s, err
+}
+
+// GetSettings looks up the schedule's settings, which are all empty until [SetSettings].
+func GetSettings(ctx context.Context, q *save.Queries, schedule string) (Settings, error) {
+	if err := knownSchedule(ctx, q, schedule); err != nil {
+		return Settings{}, err
+	}
+	out := Settings{Schedule: schedule}
+	s, err := q.GetSettings(ctx, schedule)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return Settings{}, err
+	default:
+		out.Timezone, out.Description, out.Style = s.Timezone, s.Description, s.Style
+		out.StyleParams, err = ParseStyleParams(s.Params)
+		if err != nil {
+			return Settings{}, fmt.Errorf("parsing stored parameters of schedule %q: %w", schedule, err)
+		}
+	}
+	remotes, err := q.ListRemoteSchedules(ctx, schedule)
+	if err != nil {
+		return Settings{}, err
+	}
+	for _, r := range remotes {
+		if out.Remotes == nil {
+			out.Remotes = make(map[string]string)
+		}
+		out.Remotes[r.Destination] = r.Name
+	}
+	return out, nil
+}
+
+// SetSettings replaces the schedule's settings.
+// Remotes merge into the ones it already has, and an empty remote removes its remote at that destination.
+func SetSettings(ctx context.Context, q *save.Queries, s Settings) error {
+	if err := knownSchedule(ctx, q, s.Schedule); err != nil {
+		return err
+	}
+	if err := s.check(); err != nil {
+		return err
+	}
+	if err := q.SetSettings(ctx, save.SetSettingsParams{
+		Schedule:    s.Schedule,
+		Timezone:    s.Timezone,
+		Description: s.Description,
+		Style:       s.Style,
+		Params:      s.StyleParams.String(),
+	}); err != nil {
+		return fmt.Errorf("setting %s: %w", s.Schedule, err)
+	}
+	var destinations []string
+	for d := range s.Remotes {
+		destinations = append(destinations, d)
+	}
+	sort.Strings(destinations)
+	for _, d := range destinations {
+		var err error
+		if s.Remotes[d] == "" {
+			err = q.RemoveRemoteSchedule(ctx, save.RemoveRemoteScheduleParams{Schedule: s.Schedule, Destination: d})
+		} else {
+			err = q.SetRemoteSchedule(ctx, save.SetRemoteScheduleParams{Schedule: s.Schedule, Destination: d, Name: s.Remotes[d]})
+		}
+		if err != nil {
+			return fmt.Errorf("setting remote of %s at %s: %w", s.Schedule, d, err)
+		}
+	}
+	return nil
+}
+
+// WriteSettings writes the settings as a CSV, with the remotes as destination=remote pairs separated by spaces.
+func WriteSettings(w io.Writer, s Settings) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"schedule", "timezone", "description", "style", "params", "remotes"}); err != nil {
+		return err
+	}
+	var remotes []string
+	for d, r := range s.Remotes {
+		remotes = append(remotes, d+"="+r)
+	}
+	sort.Strings(remotes)
+	if err := out.Write([]string{s.Schedule, s.Timezone, s.Description, s.Style, s.StyleParams.String(), strings.Join(remotes, " ")}); err != nil {
+		return err
+	}
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,111 @@
+package cmd_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jreut/pager/v2/pkg/assert"
+	"github.com/jreut/pager/v2/pkg/cmd"
+	"github.com/jreut/pager/v2/pkg/save"
+)
+
+func TestSettings(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+
+	const schedule = "schedule"
+	_, err := cmd.GetSettings(ctx, q, schedule)
+	assert.Error(t, `unknown schedule "schedule": add it first`, err)
+	assert.Nil(t, q.AddSchedule(ctx, schedule))
+
+	got, err := cmd.GetSettings(ctx, q, schedule)
+	assert.Nil(t, err)
+	assert.Cmp(t, cmd.Settings{Schedule: schedule}, got)
+
+	want := cmd.Settings{
+		Schedule:    schedule,
+		Timezone:    "Europe/Berlin",
+		Description: "on call",
+		Style:       "Weekly",
+		StyleParams: cmd.StyleParams{"day": "Monday"},
+		Remotes:     map[string]string{"opsgenie": "abc-123"},
+	}
+	assert.Nil(t, cmd.SetSettings(ctx, q, want))
+	got, err = cmd.GetSettings(ctx, q, schedule)
+	assert.Nil(t, err)
+	assert.Cmp(t, want, got)
+
+	// Remotes merge, and an empty one goes away.
+	assert.Nil(t, cmd.SetSettings(ctx, q, cmd.Settings{Schedule: schedule, Remotes: map[string]string{"opsgenie": "", "other": "x"}}))
+	got, err = cmd.GetSettings(ctx, q, schedule)
+	assert.Nil(t, err)
+	assert.Cmp(t, cmd.Settings{Schedule: schedule, StyleParams: cmd.StyleParams{}, Remotes: map[string]string{"other": "x"}}, got)
+	remote, err := cmd.RemoteSchedule(ctx, q, schedule, "other")
+	assert.Nil(t, err)
+	assert.Cmp(t, "x", remote)
+	remote, err = cmd.RemoteSchedule(ctx, q, schedule, "opsgenie")
+	assert.Nil(t, err)
+	assert.Cmp(t, schedule, remote)
+
+	for _, bad := range []cmd.Settings{
+		{Schedule: schedule, Timezone: "Nowhere"},
+		{Schedule: schedule, Style: "Unknown"},
+		{Schedule: schedule, Style: "Weekly", StyleParams: cmd.StyleParams{"day": "Someday"}},
+		{Schedule: schedule, StyleParams: cmd.StyleParams{"day": "Monday"}},
+		{Schedule: "unknown"},
+	} {
+		if err := cmd.SetSettings(ctx, q, bad); err == nil {
+			t.Errorf("want an error setting %+v", bad)
+		}
+	}
+}
+
+func TestGenerateSettings(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+	const schedule = "schedule"
+	assert.Nil(t, q.AddSchedule(ctx, schedule))
+	addpeople(t, ctx, q, "alice", "bob")
+	start := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	assert.Nil(t, cmd.EditSchedule(ctx, q, schedule, []cmd.Action{
+		{Kind: save.EventKindAdd, Who: "alice", At: start},
+		{Kind: save.EventKindAdd, Who: "bob", At: start},
+	}))
+	generate := func(style string, params cmd.StyleParams) error {
+		return cmd.Generate(ctx, q, cmd.GenerateParams{
+			Schedule:    schedule,
+			Style:       style,
+			StyleParams: params,
+			StartAt:     start,
+			EndBefore:   start.AddDate(0, 0, 14),
+		})
+	}
+	assert.Error(t, `provide a style, or set one for schedule "schedule"`, generate("", nil))
+
+	assert.Nil(t, cmd.SetSettings(ctx, q, cmd.Settings{
+		Schedule:    schedule,
+		Timezone:    "America/New_York",
+		Style:       "Weekly",
+		StyleParams: cmd.StyleParams{"day": "Wednesday"},
+	}))
+	for _, tt := range []struct {
+		style  string
+		params cmd.StyleParams
+		want   string
+	}{
+		// The schedule's style and parameters, without its timezone, which is only for showing times.
+		{"", nil, "day=Wednesday"},
+		// The schedule's parameters only go with its style.
+		{"EveryNDays", cmd.StyleParams{"days": "3"}, "days=3"},
+		{"Weekly", cmd.StyleParams{"day": "Friday", "tz": "UTC"}, "day=Friday,tz=UTC"},
+		// Styles without a tz parameter work whatever the schedule's timezone.
+		{"MondayAndFridayAtNoonEastern", nil, ""},
+	} {
+		assert.Nil(t, generate(tt.style, tt.params))
+		generations, err := q.ListGenerations(ctx, schedule)
+		assert.Nil(t, err)
+		last := generations[len(generations)-1]
+		assert.Cmp(t, tt.want, last.Params)
+	}
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/jreut/pager/v2/pkg/interval"
+	"github.com/jreut/pager/v2/pkg/save"
+)
+
+// ShowShadows lists who shadows the schedule during [start, end).
+//
+// Unlike shifts, shadows for different people overlap.
+func ShowShadows(ctx context.Context, q *save.Queries, schedule string, start, end time.Time) ([]save.Interval, error) {
+	all, err := q.ListIntervals(ctx, save.ListIntervalsParams{
+		Schedule:  schedule,
+		Kind:      save.IntervalKindShadow,
+		StartAt:   start,
+		EndBefore: end,
+	})
+	if err != nil {
+		return nil, err
+	}
+	byperson := make(map[string][]save.Interval)
+	for _, i := range all {
+		byperson[i.Person] = append(byperson[i.Person], i)
+	}
+	var out []save.Interval
+	for _, is := range byperson {
+		out = append(out, interval.Flatten(is)...)
+	}
+	for i := range out {
+		if out[i].StartAt.Before(start) {
+			out[i].StartAt = start
+		}
+		if out[i].EndBefore.After(end) {
+			out[i].EndBefore = end
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].StartAt.Equal(out[j].StartAt) {
+			return out[i].Person < out[j].Person
+		}
+		return out[i].StartAt.Before(out[j].StartAt)
+	})
+	return out, nil
+}
+
+// KindColumn tells shifts from shadows.
+var KindColumn = interval.Column{
+	Name:  "kind",
+	Value: func(i save.Interval) string { return i.Kind },
+}
+
+// shadowing finds when each person stops shadowing.
+// The [save.EventKindShadow] added last for each person wins, so a later edit can shorten a shadow as well as extend it.
+// Events must be in the order they were added, as [save.Queries.ListShadows] returns them.
+func shadowing(events []save.Event) map[string]time.Time {
+	out := make(map[string]time.Time)
+	for _, e := range events {
+		if e.Kind == save.EventKindShadow {
+			out[e.Person] = e.At
+		}
+	}
+	return out
+}
+
+// free splits [a, b) into the pieces when person has no exclusion.
+func free(person string, exclusions []save.Interval, a, b time.Time) [][2]time.Time {
+	var mine []save.Interval
+	for _, e := range exclusions {
+		if e.Person == person {
+			mine = append(mine, e)
+		}
+	}
+	sort.Slice(mine, func(i, j int) bool { return mine[i].StartAt.Before(mine[j].StartAt) })
+	var out [][2]time.Time
+	for _, e := range mine {
+		if e.StartAt.After(a) {
+			end := e.StartAt
+			if end.After(b) {
+				end = b
+			}
+			out = append(out, [2]time.Time{a, end})
+		}
+		if e.EndBefore.After(a) {
+			a = e.EndBefore
+		}
+		if !a.Before(b) {
+			return out
+		}
+	}
+	return append(out, [2]time.Time{a, b})
+}
@@ -8,8 +8,14 @@ import (
 	"github.com/jreut/pager/v2/pkg/save"
 )
 
+// ShowSchedule flattens the primary layer of the schedule during [start, end).
 func ShowSchedule(ctx context.Context, q *save.Queries, schedule string, start time.Time, end time.Time) ([]save.Interval, error) {
-	out, err := q.ListIntervals(ctx, save.ListIntervalsParams{
+	return ShowLayer(ctx, q, schedule, save.LayerPrimary, start, end)
+}
+
+// ShowLayer flattens one layer of the schedule during [start, end).
+func ShowLayer(ctx context.Context, q *save.Queries, schedule, layer string, start time.Time, end time.Time) ([]save.Interval, error) {
+	all, err := q.ListIntervals(ctx, save.ListIntervalsParams{
 		Schedule:  schedule,
 		Kind:      save.IntervalKindShift,
 		StartAt:   start,
@@ -18,6 +24,12 @@ func ShowSchedule(ctx context.Context, q *save.Queries, schedule string, start t
 	if err != nil {
 		return nil, err
 	}
+	var out []save.Interval
+	for _, i := range all {
+		if i.Layer == layer {
+			out = append(out, i)
+		}
+	}
 	out = interval.Flatten(out)
 	for i := range out {
 		if out[i].StartAt.Before(start) {
@@ -30,3 +42,20 @@ func ShowSchedule(ctx context.Context, q *save.Queries, schedule string, start t
 
 	return out, nil
 }
+
+// ShowLayers flattens each of the schedule's layers during [start, end), one layer after another.
+func ShowLayers(ctx context.Context, q *save.Queries, schedule string, start time.Time, end time.Time) ([]save.Interval, error) {
+	layers, err := Layers(ctx, q, schedule)
+	if err != nil {
+		return nil, err
+	}
+	var out []save.Interval
+	for _, l := range layers {
+		shifts, err := ShowLayer(ctx, q, schedule, l, start, end)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, shifts...)
+	}
+	return out, nil
+}
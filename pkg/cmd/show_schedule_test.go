@@ -8,6 +8,7 @@ import (
 	"github.com/jreut/pager/v2/pkg/assert"
 	"github.com/jreut/pager/v2/pkg/cmd"
 	"github.com/jreut/pager/v2/pkg/save"
+	"github.com/jreut/pager/v2/pkg/save/savetest"
 )
 
 func TestShowSchedule(t *testing.T) {
@@ -38,7 +39,7 @@ func TestShowSchedule(t *testing.T) {
 				{Schedule: s1, Person: alice, StartAt: t0, EndBefore: t1, Kind: save.IntervalKindShift},
 			},
 			want: []save.Interval{
-				{Schedule: s1, Person: alice, StartAt: t0, EndBefore: t1, Kind: save.IntervalKindShift},
+				{ID: 1, Schedule: s1, Person: alice, StartAt: t0, EndBefore: t1, Kind: save.IntervalKindShift},
 			},
 			schedule: s1, start: t0, end: t1,
 		},
@@ -47,7 +48,7 @@ func TestShowSchedule(t *testing.T) {
 				{Schedule: s1, Person: alice, StartAt: t1, EndBefore: t2, Kind: save.IntervalKindShift},
 			},
 			want: []save.Interval{
-				{Schedule: s1, Person: alice, StartAt: t1, EndBefore: t2, Kind: save.IntervalKindShift},
+				{ID: 1, Schedule: s1, Person: alice, StartAt: t1, EndBefore: t2, Kind: save.IntervalKindShift},
 			},
 			schedule: s1, start: t0, end: t3,
 		},
@@ -57,7 +58,7 @@ func TestShowSchedule(t *testing.T) {
 				{Schedule: s1, Person: alice, StartAt: t2, EndBefore: t3, Kind: save.IntervalKindExclusion},
 			},
 			want: []save.Interval{
-				{Schedule: s1, Person: alice, StartAt: t1, EndBefore: t2, Kind: save.IntervalKindShift},
+				{ID: 1, Schedule: s1, Person: alice, StartAt: t1, EndBefore: t2, Kind: save.IntervalKindShift},
 			},
 			schedule: s1, start: t0, end: t3,
 		},
@@ -67,7 +68,7 @@ func TestShowSchedule(t *testing.T) {
 				{Schedule: s2, Person: alice, StartAt: t2, EndBefore: t3, Kind: save.IntervalKindShift},
 			},
 			want: []save.Interval{
-				{Schedule: s1, Person: alice, StartAt: t1, EndBefore: t2, Kind: save.IntervalKindShift},
+				{ID: 1, Schedule: s1, Person: alice, StartAt: t1, EndBefore: t2, Kind: save.IntervalKindShift},
 			},
 			schedule: s1, start: t0, end: t3,
 		},
@@ -77,8 +78,8 @@ func TestShowSchedule(t *testing.T) {
 				{Schedule: s1, Person: bob, StartAt: t2, EndBefore: t4, Kind: save.IntervalKindShift},
 			},
 			want: []save.Interval{
-				{Schedule: s1, Person: alice, StartAt: t1, EndBefore: t2, Kind: save.IntervalKindShift},
-				{Schedule: s1, Person: bob, StartAt: t2, EndBefore: t3, Kind: save.IntervalKindShift},
+				{ID: 1, Schedule: s1, Person: alice, StartAt: t1, EndBefore: t2, Kind: save.IntervalKindShift},
+				{ID: 2, Schedule: s1, Person: bob, StartAt: t2, EndBefore: t3, Kind: save.IntervalKindShift},
 			},
 			schedule: s1, start: t1, end: t3,
 		},
@@ -86,11 +87,12 @@ func TestShowSchedule(t *testing.T) {
 		t.Run("", func(t *testing.T) {
 			ctx := context.Background()
 			q := save.New(testdb(t, ctx))
+			addpeople(t, ctx, q, "alice", "bob")
 			assert.Nil(t, q.AddSchedule(ctx, s1))
 			assert.Nil(t, q.AddSchedule(ctx, s2))
 
 			for _, i := range tt.setup {
-				assert.Nil(t, q.AddInterval(ctx, save.AddIntervalParams(i)))
+				assert.Nil(t, q.AddInterval(ctx, i.Params()))
 			}
 			got, err := cmd.ShowSchedule(ctx, q, tt.schedule, tt.start, tt.end)
 			assert.Nil(t, err)
@@ -98,3 +100,54 @@ func TestShowSchedule(t *testing.T) {
 		})
 	}
 }
+
+// TestShowScheduleStable checks that overrides keep their order however SQLite rearranges the rows underneath.
+func TestShowScheduleStable(t *testing.T) {
+	savetest.SQLiteOnly(t)
+	ctx := context.Background()
+	db := testdb(t, ctx)
+	q := save.New(db)
+	addpeople(t, ctx, q, "alice", "bob", "cindy", "dan")
+
+	const schedule = "schedule"
+	assert.Nil(t, q.AddSchedule(ctx, schedule))
+	day := func(n int) time.Time { return time.Date(2023, 1, 1+n, 0, 0, 0, 0, time.UTC) }
+	for _, i := range []save.AddIntervalParams{
+		{Person: "alice", StartAt: day(0), EndBefore: day(7)},
+		{Person: "bob", StartAt: day(1), EndBefore: day(3)},
+		{Person: "cindy", StartAt: day(2), EndBefore: day(5)},
+		{Person: "dan", StartAt: day(0), EndBefore: day(7), Priority: -1},
+		{Person: "bob", StartAt: day(4), EndBefore: day(6)},
+		{Person: "dan", StartAt: day(5), EndBefore: day(7)},
+	} {
+		i.Schedule, i.Kind = schedule, save.IntervalKindShift
+		assert.Nil(t, cmd.AddInterval(ctx, q, i, true))
+	}
+	// Leave gaps in the IDs, including at the end, so that VACUUM has rows to move.
+	_, err := db.ExecContext(ctx, `DELETE FROM interval WHERE id IN (2, 6)`)
+	assert.Nil(t, err)
+	assert.Nil(t, cmd.AddInterval(ctx, q, save.AddIntervalParams{
+		Person:    "dan",
+		Schedule:  schedule,
+		StartAt:   day(6),
+		EndBefore: day(7),
+		Kind:      save.IntervalKindShift,
+	}, true))
+
+	before, err := cmd.ShowSchedule(ctx, q, schedule, day(0), day(7))
+	assert.Nil(t, err)
+	var people []string
+	for _, i := range before {
+		people = append(people, i.Person)
+	}
+	assert.Cmp(t, []string{"alice", "cindy", "bob", "dan"}, people)
+	assert.Cmp(t, int64(7), before[len(before)-1].ID)
+
+	for _, stmt := range []string{`VACUUM`, `REINDEX`} {
+		_, err := db.ExecContext(ctx, stmt)
+		assert.Nil(t, err)
+		after, err := cmd.ShowSchedule(ctx, q, schedule, day(0), day(7))
+		assert.Nil(t, err)
+		assert.Cmp(t, before, after)
+	}
+}
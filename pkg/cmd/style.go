@@ -0,0 +1,337 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A Style decides when shifts change hands during [Generate].
+type Style struct {
+	Help string
+	// New builds a Rotation from the parameters given to the style.
+	New func(StyleParams) (Rotation, error)
+}
+
+// A Rotation is a Style configured with its parameters.
+type Rotation interface {
+	// Next returns the first handoff strictly after t.
+	Next(t time.Time) time.Time
+}
+
+// RotationFunc adapts a function to a [Rotation].
+type RotationFunc func(t time.Time) time.Time
+
+func (f RotationFunc) Next(t time.Time) time.Time { return f(t) }
+
+// A RegionalRotation only lets people from one region take each shift.
+//
+// People belong to a region when they have a tag with the region's name.
+// [Generate] keeps a separate tally for each region.
+type RegionalRotation interface {
+	Rotation
+	// Region names the region responsible for the shift starting at t.
+	Region(t time.Time) string
+}
+
+const (
+	StyleMondayAndFridayAtNoonEastern = "MondayAndFridayAtNoonEastern"
+	StyleWeekly                       = "Weekly"
+	StyleEveryNDays                   = "EveryNDays"
+	StyleFollowTheSun                 = "FollowTheSun"
+)
+
+// Styles are the rotation styles that [Generate] knows about, keyed by name.
+var Styles = map[string]Style{
+	StyleMondayAndFridayAtNoonEastern: {
+		Help: "Hand off at noon in America/New_York on Mondays and Fridays.",
+		New: func(p StyleParams) (Rotation, error) {
+			if err := p.only(); err != nil {
+				return nil, err
+			}
+			return RotationFunc(mondayAndFridayAtNoonEastern), nil
+		},
+	},
+	StyleWeekly: {
+		Help: "Hand off once a week. Parameters: day=<weekday>, at=<HH:MM> (default 00:00), tz=<location> (default UTC).",
+		New: func(p StyleParams) (Rotation, error) {
+			if err := p.only("day", "at", "tz"); err != nil {
+				return nil, err
+			}
+			day, err := p.weekday("day")
+			if err != nil {
+				return nil, err
+			}
+			hour, min, err := p.clock("at")
+			if err != nil {
+				return nil, err
+			}
+			loc, err := p.location("tz")
+			if err != nil {
+				return nil, err
+			}
+			// 4 January 1970 was a Sunday.
+			anchor := time.Date(1970, 1, 4+int(day), 0, 0, 0, 0, loc)
+			return every(7, anchor, hour, min), nil
+		},
+	},
+	StyleEveryNDays: {
+		Help: "Hand off every few days. Parameters: days=<N>, at=<HH:MM> (default 00:00), tz=<location> (default UTC), from=<YYYY-MM-DD> (default 1970-01-01) to count days from.",
+		New: func(p StyleParams) (Rotation, error) {
+			if err := p.only("days", "at", "tz", "from"); err != nil {
+				return nil, err
+			}
+			n, err := strconv.Atoi(p["days"])
+			if err != nil {
+				return nil, fmt.Errorf("parsing days=%q: %w", p["days"], err)
+			}
+			if n < 1 {
+				return nil, fmt.Errorf("days=%d must be positive", n)
+			}
+			hour, min, err := p.clock("at")
+			if err != nil {
+				return nil, err
+			}
+			loc, err := p.location("tz")
+			if err != nil {
+				return nil, err
+			}
+			anchor := time.Date(1970, 1, 1, 0, 0, 0, 0, loc)
+			if v, ok := p["from"]; ok {
+				anchor, err = time.ParseInLocation("2006-01-02", v, loc)
+				if err != nil {
+					return nil, fmt.Errorf("parsing from=%q: %w", v, err)
+				}
+			}
+			return every(n, anchor, hour, min), nil
+		},
+	},
+	StyleFollowTheSun: {
+		Help: "Split each day into regional windows. Parameters: <region>=<HH:MM> for when each region's window starts, tz=<location> (default UTC). Only people tagged with a region take its shifts.",
+		New: func(p StyleParams) (Rotation, error) {
+			loc, err := p.location("tz")
+			if err != nil {
+				return nil, err
+			}
+			var out followTheSun
+			for k := range p {
+				if k == "tz" {
+					continue
+				}
+				hour, min, err := p.clock(k)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, window{
+					region: k,
+					every:  every(1, time.Date(1970, 1, 1, 0, 0, 0, 0, loc), hour, min),
+				})
+			}
+			if len(out) == 0 {
+				return nil, fmt.Errorf("provide at least one <region>=<HH:MM>")
+			}
+			return out, nil
+		},
+	},
+}
+
+// StyleNames returns the names of [Styles] in sorted order.
+func StyleNames() []string {
+	var out []string
+	for k := range Styles {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// NewRotation looks up the named style and builds its Rotation.
+func NewRotation(style string, params StyleParams) (Rotation, error) {
+	st, ok := Styles[style]
+	if !ok {
+		return nil, fmt.Errorf("unhandled style %q: choose one of %s", style, StyleNames())
+	}
+	r, err := st.New(params)
+	if err != nil {
+		return nil, fmt.Errorf("style %s: %w", style, err)
+	}
+	return r, nil
+}
+
+// StyleParams configure a [Style].
+type StyleParams map[string]string
+
+// ParseStyleParams parses a comma-separated list of key=value pairs, like "day=Tuesday,at=10:00".
+func ParseStyleParams(s string) (StyleParams, error) {
+	out := make(StyleParams)
+	if s == "" {
+		return out, nil
+	}
+	for _, kv := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, fmt.Errorf("cannot parse %q: does not contain %q", kv, "=")
+		}
+		if _, ok := out[k]; ok {
+			return nil, fmt.Errorf("duplicate parameter %q", k)
+		}
+		out[k] = v
+	}
+	return out, nil
+}
+
+func (p StyleParams) String() string {
+	var out []string
+	for k, v := range p {
+		out = append(out, k+"="+v)
+	}
+	sort.Strings(out)
+	return strings.Join(out, ",")
+}
+
+func (p StyleParams) only(keys ...string) error {
+	for k := range p {
+		var ok bool
+		for _, key := range keys {
+			ok = ok || k == key
+		}
+		if !ok {
+			return fmt.Errorf("unknown parameter %q: choose from %s", k, keys)
+		}
+	}
+	return nil
+}
+
+func (p StyleParams) weekday(key string) (time.Weekday, error) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(p[key], d.String()) {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("parsing %s=%q: not a day of the week", key, p[key])
+}
+
+func (p StyleParams) clock(key string) (hour, min int, err error) {
+	v, ok := p[key]
+	if !ok {
+		return 0, 0, nil
+	}
+	t, err := time.Parse("15:04", v)
+	if err != nil {
+		return 0, 0, fmt.Errorf("parsing %s=%q: %w", key, v, err)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+func (p StyleParams) location(key string) (*time.Location, error) {
+	v, ok := p[key]
+	if !ok {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(v)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s=%q: %w", key, v, err)
+	}
+	return loc, nil
+}
+
+// every hands off every n days at hour:min, counting days from the date of anchor.
+//
+// Handoffs happen at the same wall clock time in anchor's location, so they follow daylight saving time.
+func every(n int, anchor time.Time, hour, min int) RotationFunc {
+	loc := anchor.Location()
+	return func(t time.Time) time.Time {
+		local := t.In(loc)
+		days := civildays(anchor, local)
+		k := days / n
+		if days%n < 0 {
+			k--
+		}
+		for {
+			next := time.Date(anchor.Year(), anchor.Month(), anchor.Day()+k*n, hour, min, 0, 0, loc)
+			if next.After(t) {
+				return next
+			}
+			k++
+		}
+	}
+}
+
+type window struct {
+	region string
+	every  RotationFunc
+}
+
+// followTheSun hands off to each region at the start of its daily window.
+type followTheSun []window
+
+func (f followTheSun) Next(t time.Time) time.Time {
+	var out time.Time
+	for i, w := range f {
+		if next := w.every(t); i == 0 || next.Before(out) {
+			out = next
+		}
+	}
+	return out
+}
+
+// Region finds the window whose most recent start is closest to t.
+func (f followTheSun) Region(t time.Time) string {
+	var (
+		out    string
+		latest time.Time
+	)
+	for i, w := range f {
+		// Each window starts once a day, so the start before the next one is the latest at or before t.
+		start := w.every(t).AddDate(0, 0, -1)
+		if i == 0 || start.After(latest) || (start.Equal(latest) && w.region < out) {
+			out, latest = w.region, start
+		}
+	}
+	return out
+}
+
+// civildays counts the calendar days from a to b, ignoring their times of day.
+func civildays(a, b time.Time) int {
+	da := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	db := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(db.Sub(da).Hours() / 24)
+}
+
+var edt = func() *time.Location {
+	edt, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		panic(err)
+	}
+	return edt
+}()
+
+func mondayAndFridayAtNoonEastern(t time.Time) time.Time {
+	noon := time.Date(t.Year(), t.Month(), t.Day(), 12, 0, 0, 0, edt)
+	switch t.In(edt).Weekday() {
+	case time.Sunday:
+		return noon.AddDate(0, 0, 1)
+	case time.Monday:
+		if !t.Before(noon) {
+			return noon.AddDate(0, 0, 4)
+		}
+		return noon
+	case time.Tuesday:
+		return noon.AddDate(0, 0, 3)
+	case time.Wednesday:
+		return noon.AddDate(0, 0, 2)
+	case time.Thursday:
+		return noon.AddDate(0, 0, 1)
+	case time.Friday:
+		if !t.Before(noon) {
+			return noon.AddDate(0, 0, 3)
+		}
+		return noon
+	case time.Saturday:
+		return noon.AddDate(0, 0, 2)
+	default:
+		panic(fmt.Sprintf("unhandled t.Weekday() %v", t.Weekday()))
+	}
+}
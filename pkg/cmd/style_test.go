@@ -0,0 +1,131 @@
+package cmd_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jreut/pager/v2/pkg/assert"
+	"github.com/jreut/pager/v2/pkg/cmd"
+)
+
+func TestNewRotation(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.Nil(t, err)
+
+	for _, tt := range []struct {
+		style, params string
+		at            time.Time
+		want          time.Time
+		err           string
+	}{
+		{
+			style: "unknown",
+			err:   `unhandled style "unknown"`,
+		},
+		{
+			style:  cmd.StyleMondayAndFridayAtNoonEastern,
+			params: "day=Monday",
+			err:    `unknown parameter "day"`,
+		},
+		{
+			style:  cmd.StyleWeekly,
+			params: "day=Someday",
+			err:    `day="Someday": not a day of the week`,
+		},
+		{
+			style:  cmd.StyleWeekly,
+			params: "day=Monday,at=25:00",
+			err:    `at="25:00"`,
+		},
+		{
+			style:  cmd.StyleWeekly,
+			params: "day=Monday,tz=Nowhere/Special",
+			err:    `tz="Nowhere/Special"`,
+		},
+		{
+			style:  cmd.StyleEveryNDays,
+			params: "days=0",
+			err:    "must be positive",
+		},
+		{
+			style:  cmd.StyleWeekly,
+			params: "day=Tuesday,at=10:00,tz=Europe/Berlin",
+			at:     time.Date(2023, 3, 21, 10, 0, 0, 0, berlin),
+			want:   time.Date(2023, 3, 28, 10, 0, 0, 0, berlin),
+		},
+		{
+			style:  cmd.StyleWeekly,
+			params: "day=Tuesday,at=10:00,tz=Europe/Berlin",
+			at:     time.Date(2023, 3, 21, 9, 59, 0, 0, berlin),
+			want:   time.Date(2023, 3, 21, 10, 0, 0, 0, berlin),
+		},
+		{
+			style:  cmd.StyleEveryNDays,
+			params: "days=3,from=2023-01-10",
+			at:     time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			want:   time.Date(2023, 1, 4, 0, 0, 0, 0, time.UTC),
+		},
+	} {
+		t.Run("", func(t *testing.T) {
+			params, err := cmd.ParseStyleParams(tt.params)
+			assert.Nil(t, err)
+			next, err := cmd.NewRotation(tt.style, params)
+			if tt.err != "" {
+				assert.Error(t, tt.err, err)
+				return
+			}
+			assert.Nil(t, err)
+			got := next.Next(tt.at)
+			if !got.Equal(tt.want) {
+				t.Fatalf("want %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFollowTheSun(t *testing.T) {
+	params, err := cmd.ParseStyleParams("apac=00:00,emea=08:00,amer=16:00")
+	assert.Nil(t, err)
+	r, err := cmd.NewRotation(cmd.StyleFollowTheSun, params)
+	assert.Nil(t, err)
+	regional, ok := r.(cmd.RegionalRotation)
+	if !ok {
+		t.Fatalf("%T is not a cmd.RegionalRotation", r)
+	}
+	for _, tt := range []struct {
+		at     time.Time
+		next   time.Time
+		region string
+	}{
+		{
+			at:     time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			next:   time.Date(2023, 1, 1, 8, 0, 0, 0, time.UTC),
+			region: "apac",
+		},
+		{
+			at:     time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC),
+			next:   time.Date(2023, 1, 1, 16, 0, 0, 0, time.UTC),
+			region: "emea",
+		},
+		{
+			at:     time.Date(2023, 1, 1, 23, 59, 0, 0, time.UTC),
+			next:   time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+			region: "amer",
+		},
+	} {
+		assert.Cmp(t, tt.next, regional.Next(tt.at))
+		assert.Cmp(t, tt.region, regional.Region(tt.at))
+	}
+}
+
+func TestParseStyleParams(t *testing.T) {
+	got, err := cmd.ParseStyleParams("day=Tuesday,at=10:00")
+	assert.Nil(t, err)
+	assert.Cmp(t, cmd.StyleParams{"day": "Tuesday", "at": "10:00"}, got)
+	assert.Cmp(t, "at=10:00,day=Tuesday", got.String())
+
+	_, err = cmd.ParseStyleParams("day")
+	assert.Error(t, `does not contain "="`, err)
+	_, err = cmd.ParseStyleParams("day=Monday,day=Tuesday")
+	assert.Error(t, `duplicate parameter "day"`, err)
+}
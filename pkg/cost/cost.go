@@ -0,0 +1,191 @@
+// Package cost weighs time spent on call.
+package cost
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Model weighs each hour on call by when it happens.
+//
+// Every weight that applies to an hour multiplies together.
+// Hours without any weights count once, so the zero Model counts raw duration.
+type Model struct {
+	// Location decides the weekday, hour, and date of each hour.
+	// A nil Location means UTC.
+	Location *time.Location
+	Weekdays map[time.Weekday]float64
+	Hours    []Hours
+	// Dates maps days, formatted as "2006-01-02", to their weights.
+	Dates map[string]float64
+	// Holiday weighs the time in Holidays.
+	// A zero Holiday ignores Holidays.
+	Holiday  float64
+	Holidays []Period
+}
+
+// Period is the time in [StartAt, EndBefore).
+type Period struct {
+	StartAt, EndBefore time.Time
+}
+
+func (p Period) contains(t time.Time) bool {
+	return !t.Before(p.StartAt) && t.Before(p.EndBefore)
+}
+
+// Hours weighs the hours of each day in [From, To).
+//
+// Ranges can wrap around midnight, like 22-06.
+type Hours struct {
+	From, To int
+	Weight   float64
+}
+
+func (h Hours) contains(hour int) bool {
+	if h.From <= h.To {
+		return h.From <= hour && hour < h.To
+	}
+	return h.From <= hour || hour < h.To
+}
+
+const dateformat = "2006-01-02"
+
+// Parse reads a comma-separated list of key=weight pairs.
+//
+// Keys are weekdays like "saturday", hour ranges like "22-06", or dates like "2023-12-25".
+// The key "holiday" weighs the Holidays, which callers fill in separately.
+// The special key "tz" sets the Location instead of a weight.
+func Parse(s string) (Model, error) {
+	var m Model
+	if s == "" {
+		return m, nil
+	}
+	for _, kv := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return Model{}, fmt.Errorf("cannot parse %q: does not contain %q", kv, "=")
+		}
+		if k == "tz" {
+			loc, err := time.LoadLocation(v)
+			if err != nil {
+				return Model{}, fmt.Errorf("parsing %s=%q: %w", k, v, err)
+			}
+			m.Location = loc
+			continue
+		}
+		w, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return Model{}, fmt.Errorf("parsing weight %s=%q: %w", k, v, err)
+		}
+		if w < 0 {
+			return Model{}, fmt.Errorf("weight %s=%v must not be negative", k, w)
+		}
+		if k == "holiday" {
+			m.Holiday = w
+			continue
+		}
+		if d, ok := weekday(k); ok {
+			if m.Weekdays == nil {
+				m.Weekdays = make(map[time.Weekday]float64)
+			}
+			m.Weekdays[d] = w
+			continue
+		}
+		if from, to, ok := strings.Cut(k, "-"); ok && len(k) <= len("00-00") {
+			h := Hours{Weight: w}
+			if h.From, err = hour(from); err != nil {
+				return Model{}, fmt.Errorf("parsing hours %q: %w", k, err)
+			}
+			if h.To, err = hour(to); err != nil {
+				return Model{}, fmt.Errorf("parsing hours %q: %w", k, err)
+			}
+			m.Hours = append(m.Hours, h)
+			continue
+		}
+		if _, err := time.Parse(dateformat, k); err == nil {
+			if m.Dates == nil {
+				m.Dates = make(map[string]float64)
+			}
+			m.Dates[k] = w
+			continue
+		}
+		return Model{}, fmt.Errorf("cannot parse %q: want a weekday, an hour range like 22-06, or a date like 2006-01-02", k)
+	}
+	return m, nil
+}
+
+func weekday(s string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(s, d.String()) {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+func hour(s string) (int, error) {
+	h, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err
+	}
+	if h < 0 || h > 24 {
+		return 0, fmt.Errorf("hour %d out of range [0, 24]", h)
+	}
+	return h, nil
+}
+
+// Of weighs the time in [a, b).
+func (m Model) Of(a, b time.Time) time.Duration {
+	loc := m.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	var out time.Duration
+	for a.Before(b) {
+		local := a.In(loc)
+		next := time.Date(local.Year(), local.Month(), local.Day(), local.Hour()+1, 0, 0, 0, loc)
+		if !next.After(a) {
+			// Wall clocks can repeat an hour when daylight saving time ends.
+			next = a.Truncate(time.Hour).Add(time.Hour)
+		}
+		for _, h := range m.Holidays {
+			for _, x := range []time.Time{h.StartAt, h.EndBefore} {
+				if x.After(a) && x.Before(next) {
+					next = x
+				}
+			}
+		}
+		if next.After(b) {
+			next = b
+		}
+		out += time.Duration(float64(next.Sub(a)) * m.weight(local))
+		a = next
+	}
+	return out
+}
+
+func (m Model) weight(t time.Time) float64 {
+	out := 1.0
+	if w, ok := m.Weekdays[t.Weekday()]; ok {
+		out *= w
+	}
+	for _, h := range m.Hours {
+		if h.contains(t.Hour()) {
+			out *= h.Weight
+		}
+	}
+	if w, ok := m.Dates[t.Format(dateformat)]; ok {
+		out *= w
+	}
+	if m.Holiday != 0 {
+		for _, h := range m.Holidays {
+			if h.contains(t) {
+				out *= m.Holiday
+				break
+			}
+		}
+	}
+	return out
+}
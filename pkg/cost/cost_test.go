@@ -0,0 +1,114 @@
+package cost
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jreut/pager/v2/pkg/assert"
+)
+
+func TestParse(t *testing.T) {
+	edt, err := time.LoadLocation("America/New_York")
+	assert.Nil(t, err)
+	for _, tt := range []struct {
+		in   string
+		want Model
+		err  string
+	}{
+		{},
+		{
+			in: "tz=America/New_York,saturday=2,Sunday=2,22-06=1.5,2023-12-25=3,holiday=2.5",
+			want: Model{
+				Location: edt,
+				Weekdays: map[time.Weekday]float64{time.Saturday: 2, time.Sunday: 2},
+				Hours:    []Hours{{From: 22, To: 6, Weight: 1.5}},
+				Dates:    map[string]float64{"2023-12-25": 3},
+				Holiday:  2.5,
+			},
+		},
+		{in: "saturday", err: `does not contain "="`},
+		{in: "saturday=lots", err: "parsing weight"},
+		{in: "saturday=-1", err: "must not be negative"},
+		{in: "22-25=1", err: "out of range"},
+		{in: "someday=1", err: `cannot parse "someday"`},
+		{in: "tz=Nowhere/Special", err: "Nowhere/Special"},
+	} {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if tt.err != "" {
+				assert.Error(t, tt.err, err)
+				return
+			}
+			assert.Nil(t, err)
+			if got.Location.String() != tt.want.Location.String() {
+				t.Fatalf("want location %s, got %s", tt.want.Location, got.Location)
+			}
+			got.Location, tt.want.Location = nil, nil
+			assert.Cmp(t, tt.want, got)
+		})
+	}
+}
+
+func TestOf(t *testing.T) {
+	// 6 January 2023 was a Friday.
+	friday := time.Date(2023, 1, 6, 0, 0, 0, 0, time.UTC)
+	weekend := Model{Weekdays: map[time.Weekday]float64{time.Saturday: 2, time.Sunday: 2}}
+	nights := Model{Hours: []Hours{{From: 22, To: 6, Weight: 1.5}}}
+	both := Model{Weekdays: weekend.Weekdays, Hours: nights.Hours}
+	holiday := Model{Dates: map[string]float64{"2023-01-06": 3}}
+	// A holiday from 07:30 to 08:30 doesn't line up with hours.
+	holidays := Model{Holiday: 2, Holidays: []Period{
+		{StartAt: friday.Add(450 * time.Minute), EndBefore: friday.Add(510 * time.Minute)},
+	}}
+
+	for _, tt := range []struct {
+		m    Model
+		a, b time.Time
+		want time.Duration
+	}{
+		{
+			m: Model{}, a: friday, b: friday.AddDate(0, 0, 3),
+			want: 72 * time.Hour,
+		},
+		{
+			m: weekend, a: friday, b: friday.AddDate(0, 0, 3),
+			want: 24*time.Hour + 2*48*time.Hour,
+		},
+		{
+			m: nights, a: friday, b: friday.AddDate(0, 0, 1),
+			want: 16*time.Hour + 8*90*time.Minute,
+		},
+		{
+			// Saturday night is 2 * 1.5 = 3 times as heavy.
+			m: both, a: friday.Add(46 * time.Hour), b: friday.Add(47 * time.Hour),
+			want: 3 * time.Hour,
+		},
+		{
+			m: holiday, a: friday.Add(-30 * time.Minute), b: friday.Add(30 * time.Minute),
+			want: 30*time.Minute + 90*time.Minute,
+		},
+		{
+			m: holidays, a: friday.Add(7 * time.Hour), b: friday.Add(9 * time.Hour),
+			want: 3 * time.Hour,
+		},
+		{
+			m: Model{Holidays: holidays.Holidays}, a: friday.Add(7 * time.Hour), b: friday.Add(9 * time.Hour),
+			want: 2 * time.Hour,
+		},
+	} {
+		t.Run("", func(t *testing.T) {
+			assert.Cmp(t, tt.want, tt.m.Of(tt.a, tt.b))
+		})
+	}
+}
+
+func TestOfDaylightSaving(t *testing.T) {
+	edt, err := time.LoadLocation("America/New_York")
+	assert.Nil(t, err)
+	m := Model{Location: edt, Hours: []Hours{{From: 1, To: 2, Weight: 2}}}
+	// On 5 November 2023, 01:00 to 02:00 happens twice in New York.
+	a := time.Date(2023, 11, 5, 0, 0, 0, 0, edt)
+	b := time.Date(2023, 11, 5, 3, 0, 0, 0, edt)
+	assert.Cmp(t, 4*time.Hour, b.Sub(a))
+	assert.Cmp(t, 6*time.Hour, m.Of(a, b))
+}
@@ -3,6 +3,7 @@ package global
 import (
 	"flag"
 	"os"
+	"time"
 )
 
 // Deterministic tells whether the program should vary due to randomness or time.
@@ -13,3 +14,13 @@ import (
 func Deterministic() bool {
 	return flag.Lookup("test.short") != nil || os.Getenv("DETERMINISTIC") == "1"
 }
+
+// Now returns the current time in UTC.
+//
+// When Deterministic, Now always returns the same time instead.
+func Now() time.Time {
+	if Deterministic() {
+		return time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Now().UTC()
+}
@@ -0,0 +1,161 @@
+// Package holiday reads holiday calendars into [save.Holiday]s.
+package holiday
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jreut/pager/v2/pkg/save"
+)
+
+const dateformat = "2006-01-02"
+
+// ReadCSV reads records of the form "date,name", like "2023-12-25,Christmas Day".
+//
+// Each holiday lasts from midnight to midnight in loc.
+func ReadCSV(r io.Reader, schedule string, loc *time.Location) ([]save.Holiday, error) {
+	var out []save.Holiday
+	csv := csv.NewReader(r)
+	csv.ReuseRecord = true
+	csv.FieldsPerRecord = 2
+	csv.Comment = '#'
+	for {
+		record, err := csv.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		if record[0] == "date" {
+			continue
+		}
+		day, err := time.ParseInLocation(dateformat, record[0], loc)
+		if err != nil {
+			return out, err
+		}
+		out = append(out, save.Holiday{
+			Schedule:  schedule,
+			Name:      record[1],
+			StartAt:   day,
+			EndBefore: day.AddDate(0, 0, 1),
+		})
+	}
+	return out, nil
+}
+
+// ReadICS reads the events from an iCalendar file, as described by RFC 5545.
+//
+// All-day events and events without a time zone happen in loc.
+// Events without an end last one day.
+// Only an event's own properties count, not those of components inside it like alarms.
+// Recurring events are an error, since each holiday needs its own event.
+func ReadICS(r io.Reader, schedule string, loc *time.Location) ([]save.Holiday, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		out   []save.Holiday
+		event *save.Holiday
+		// nested names the components inside the event that the line is in.
+		nested []string
+	)
+	for n, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: cannot parse %q: does not contain %q", n+1, line, ":")
+		}
+		name, params, _ := strings.Cut(name, ";")
+		switch {
+		case event != nil && name == "BEGIN":
+			nested = append(nested, value)
+		case len(nested) > 0 && name == "END":
+			if last := nested[len(nested)-1]; value != last {
+				return nil, fmt.Errorf("line %d: END:%s inside BEGIN:%s", n+1, value, last)
+			}
+			nested = nested[:len(nested)-1]
+		case len(nested) > 0:
+			continue
+		case name == "BEGIN" && value == "VEVENT":
+			event = &save.Holiday{Schedule: schedule}
+		case name == "END" && value == "VEVENT":
+			if event == nil {
+				return nil, fmt.Errorf("line %d: END:VEVENT without BEGIN:VEVENT", n+1)
+			}
+			if event.StartAt.IsZero() {
+				return nil, fmt.Errorf("line %d: event %q has no DTSTART", n+1, event.Name)
+			}
+			if event.EndBefore.IsZero() {
+				event.EndBefore = event.StartAt.AddDate(0, 0, 1)
+			}
+			out = append(out, *event)
+			event = nil
+		case event == nil:
+			continue
+		case name == "SUMMARY":
+			event.Name = unescape(value)
+		case name == "DTSTART":
+			event.StartAt, err = icstime(params, value, loc)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n+1, err)
+			}
+		case name == "DTEND":
+			event.EndBefore, err = icstime(params, value, loc)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n+1, err)
+			}
+		case name == "RRULE" || name == "RDATE":
+			return nil, fmt.Errorf("line %d: cannot read recurring events: list each holiday as an event of its own", n+1)
+		}
+	}
+	return out, nil
+}
+
+// unfold joins the lines that RFC 5545 splits with a leading space or tab.
+func unfold(r io.Reader) ([]string, error) {
+	var out []string
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := strings.TrimRight(s.Text(), "\r")
+		if len(out) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			out[len(out)-1] += line[1:]
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		out = append(out, line)
+	}
+	return out, s.Err()
+}
+
+// icstime parses DATE and DATE-TIME values.
+func icstime(params, value string, loc *time.Location) (time.Time, error) {
+	for _, p := range strings.Split(params, ";") {
+		k, v, _ := strings.Cut(p, "=")
+		if k == "TZID" {
+			var err error
+			loc, err = time.LoadLocation(v)
+			if err != nil {
+				return time.Time{}, err
+			}
+		}
+	}
+	if len(value) == len("20060102") {
+		return time.ParseInLocation("20060102", value, loc)
+	}
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse("20060102T150405Z", value)
+	}
+	return time.ParseInLocation("20060102T150405", value, loc)
+}
+
+func unescape(s string) string {
+	return strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, "\n", `\N`, "\n", `\\`, `\`).Replace(s)
+}
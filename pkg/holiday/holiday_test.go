@@ -0,0 +1,110 @@
+package holiday
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jreut/pager/v2/pkg/assert"
+	"github.com/jreut/pager/v2/pkg/save"
+)
+
+func TestReadCSV(t *testing.T) {
+	edt, err := time.LoadLocation("America/New_York")
+	assert.Nil(t, err)
+	got, err := ReadCSV(strings.NewReader(`date,name
+# Comments are ok.
+2023-11-23,Thanksgiving Day
+2023-12-25,"Christmas Day, observed"
+`), "s", edt)
+	assert.Nil(t, err)
+	assert.Cmp(t, []save.Holiday{
+		{
+			Schedule:  "s",
+			Name:      "Thanksgiving Day",
+			StartAt:   time.Date(2023, 11, 23, 0, 0, 0, 0, edt),
+			EndBefore: time.Date(2023, 11, 24, 0, 0, 0, 0, edt),
+		},
+		{
+			Schedule:  "s",
+			Name:      "Christmas Day, observed",
+			StartAt:   time.Date(2023, 12, 25, 0, 0, 0, 0, edt),
+			EndBefore: time.Date(2023, 12, 26, 0, 0, 0, 0, edt),
+		},
+	}, got)
+
+	_, err = ReadCSV(strings.NewReader("12/25/2023,Christmas Day\n"), "s", edt)
+	assert.Error(t, "cannot parse", err)
+}
+
+func TestReadICS(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.Nil(t, err)
+	got, err := ReadICS(strings.NewReader(strings.ReplaceAll(`BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Example//Holidays//EN
+BEGIN:VTIMEZONE
+TZID:America/New_York
+BEGIN:STANDARD
+DTSTART:19701101T020000
+RRULE:FREQ=YEARLY;BYMONTH=11;BYDAY=1SU
+TZOFFSETFROM:-0400
+TZOFFSETTO:-0500
+END:STANDARD
+END:VTIMEZONE
+BEGIN:VEVENT
+UID:1
+DTSTART;VALUE=DATE:20231225
+DTEND;VALUE=DATE:20231227
+SUMMARY:Christmas Day\, and the day after
+BEGIN:VALARM
+ACTION:DISPLAY
+TRIGGER:-PT15M
+SUMMARY:Reminder
+DTSTART:20231224T000000Z
+END:VALARM
+END:VEVENT
+BEGIN:VEVENT
+UID:2
+DTSTART;VALUE=DATE:20240101
+SUMMARY:New Year's
+  Day
+END:VEVENT
+BEGIN:VEVENT
+UID:3
+DTSTART:20231231T230000Z
+DTEND;TZID=America/New_York:20240101T000000
+SUMMARY:Party
+END:VEVENT
+END:VCALENDAR
+`, "\n", "\r\n")), "s", berlin)
+	assert.Nil(t, err)
+	edt, err := time.LoadLocation("America/New_York")
+	assert.Nil(t, err)
+	assert.Cmp(t, []save.Holiday{
+		{
+			Schedule:  "s",
+			Name:      "Christmas Day, and the day after",
+			StartAt:   time.Date(2023, 12, 25, 0, 0, 0, 0, berlin),
+			EndBefore: time.Date(2023, 12, 27, 0, 0, 0, 0, berlin),
+		},
+		{
+			Schedule:  "s",
+			Name:      "New Year's Day",
+			StartAt:   time.Date(2024, 1, 1, 0, 0, 0, 0, berlin),
+			EndBefore: time.Date(2024, 1, 2, 0, 0, 0, 0, berlin),
+		},
+		{
+			Schedule:  "s",
+			Name:      "Party",
+			StartAt:   time.Date(2023, 12, 31, 23, 0, 0, 0, time.UTC),
+			EndBefore: time.Date(2024, 1, 1, 0, 0, 0, 0, edt),
+		},
+	}, got)
+
+	_, err = ReadICS(strings.NewReader("BEGIN:VEVENT\nSUMMARY:Nothing\nEND:VEVENT\n"), "s", berlin)
+	assert.Error(t, `event "Nothing" has no DTSTART`, err)
+
+	_, err = ReadICS(strings.NewReader("BEGIN:VEVENT\nDTSTART;VALUE=DATE:20231225\nRRULE:FREQ=YEARLY\nSUMMARY:Christmas Day\nEND:VEVENT\n"), "s", berlin)
+	assert.Error(t, `line 3: cannot read recurring events`, err)
+}
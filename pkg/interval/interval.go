@@ -11,8 +11,10 @@ import (
 //
 // It treats the order of the list as the order in which intervals were added to the system.
 // Intervals added later overwrite previous intervals.
+// Flatten ignores intervals that have been removed.
 // Flatten returns a chronologically sorted list.
 func Flatten(in []save.Interval) []save.Interval {
+	in = live(in)
 	if len(in) < 2 {
 		return in
 	}
@@ -34,6 +36,23 @@ func Flatten(in []save.Interval) []save.Interval {
 	return out
 }
 
+// live returns the intervals that haven't been removed.
+func live(in []save.Interval) []save.Interval {
+	for i, s := range in {
+		if !s.RemovedAt.Valid {
+			continue
+		}
+		out := append([]save.Interval(nil), in[:i]...)
+		for _, s := range in[i+1:] {
+			if !s.RemovedAt.Valid {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return in
+}
+
 // bounds finds the smallest slice of xs that are relevant to y.
 //
 // A given x in xs is relevant if it overlaps with y.
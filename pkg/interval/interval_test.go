@@ -1,6 +1,7 @@
 package interval
 
 import (
+	"database/sql"
 	"fmt"
 	"testing"
 	"time"
@@ -150,6 +151,15 @@ func TestFlatten(t *testing.T) {
 				{Person: bob, StartAt: t4, EndBefore: t5},
 			},
 		},
+		{
+			in: []save.Interval{
+				{Person: alice, StartAt: t0, EndBefore: t3},
+				{Person: bob, StartAt: t1, EndBefore: t2, RemovedAt: sql.NullTime{Time: t5, Valid: true}},
+			},
+			want: []save.Interval{
+				{Person: alice, StartAt: t0, EndBefore: t3},
+			},
+		},
 	} {
 		t.Run("", func(t *testing.T) {
 			got := Flatten(tt.in)
@@ -3,35 +3,59 @@ package interval
 import (
 	"encoding/csv"
 	"errors"
+	"fmt"
 	"io"
 	"time"
 
 	"github.com/jreut/pager/v2/pkg/save"
 )
 
-func WriteCSV(w io.Writer, out []save.Interval) error {
+// Column is an extra column for [WriteCSV].
+type Column struct {
+	Name  string
+	Value func(save.Interval) string
+}
+
+// WriteCSV writes intervals in the format that [ReadCSV] reads.
+//
+// ReadCSV ignores any extra columns except "layer" and "kind".
+func WriteCSV(w io.Writer, out []save.Interval, columns ...Column) error {
 	csv := csv.NewWriter(w)
 	defer csv.Flush()
-	if err := csv.Write([]string{"start_at", "end_before", "person"}); err != nil {
+	header := []string{"start_at", "end_before", "person"}
+	for _, c := range columns {
+		header = append(header, c.Name)
+	}
+	if err := csv.Write(header); err != nil {
 		return err
 	}
 	for _, i := range out {
-		if err := csv.Write([]string{
+		record := []string{
 			i.StartAt.Format(time.RFC3339),
 			i.EndBefore.Format(time.RFC3339),
 			i.Person,
-		}); err != nil {
+		}
+		for _, c := range columns {
+			record = append(record, c.Value(i))
+		}
+		if err := csv.Write(record); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// ReadCSV reads intervals in the format that [WriteCSV] writes.
+//
+// Intervals have the given kind unless the header names a "kind" column.
 func ReadCSV(r io.Reader, schedule, kind string) ([]save.Interval, error) {
 	var out []save.Interval
+	// layer and kindAt are the indexes of the layer and kind columns, if the header has them.
+	layer, kindAt := -1, -1
 	csv := csv.NewReader(r)
 	csv.ReuseRecord = true
-	csv.FieldsPerRecord = 3
+	// Every record has as many fields as the first.
+	csv.FieldsPerRecord = 0
 	csv.Comment = '#'
 	for {
 		record, err := csv.Read()
@@ -41,7 +65,19 @@ func ReadCSV(r io.Reader, schedule, kind string) ([]save.Interval, error) {
 			}
 			return nil, err
 		}
+		if len(record) < 3 {
+			return nil, fmt.Errorf("want at least 3 fields, got %d: %q", len(record), record)
+		}
 		if record[0] == "start_at" {
+			layer, kindAt = -1, -1
+			for i, name := range record {
+				switch name {
+				case "layer":
+					layer = i
+				case "kind":
+					kindAt = i
+				}
+			}
 			continue
 		}
 		start, err := time.Parse(time.RFC3339, record[0])
@@ -52,13 +88,20 @@ func ReadCSV(r io.Reader, schedule, kind string) ([]save.Interval, error) {
 		if err != nil {
 			return out, err
 		}
-		out = append(out, save.Interval{
+		i := save.Interval{
 			Person:    record[2],
 			Schedule:  schedule,
 			StartAt:   start,
 			EndBefore: end,
 			Kind:      kind,
-		})
+		}
+		if layer >= 0 {
+			i.Layer = record[layer]
+		}
+		if kindAt >= 0 {
+			i.Kind = record[kindAt]
+		}
+		out = append(out, i)
 	}
 	return out, nil
 }
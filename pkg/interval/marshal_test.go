@@ -0,0 +1,47 @@
+package interval
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jreut/pager/v2/pkg/assert"
+	"github.com/jreut/pager/v2/pkg/save"
+)
+
+func TestCSV(t *testing.T) {
+	in := []save.Interval{
+		{Person: alice, Schedule: "s", StartAt: t0, EndBefore: t1, Kind: save.IntervalKindShift},
+		{Person: bob, Schedule: "s", StartAt: t1, EndBefore: t2, Kind: save.IntervalKindShift},
+	}
+	var buf bytes.Buffer
+	assert.Nil(t, WriteCSV(&buf, in, Column{
+		Name:  "note",
+		Value: func(i save.Interval) string { return "hi " + i.Person },
+	}))
+	assert.Cmp(t, `start_at,end_before,person,note
+1970-01-01T00:00:00Z,1970-01-01T00:01:00Z,alice,hi alice
+1970-01-01T00:01:00Z,1970-01-01T00:02:00Z,bob,hi bob
+`, buf.String())
+
+	got, err := ReadCSV(&buf, "s", save.IntervalKindShift)
+	assert.Nil(t, err)
+	assert.Cmp(t, in, got)
+
+	_, err = ReadCSV(bytes.NewBufferString("1970-01-01T00:00:00Z,alice\n"), "s", save.IntervalKindShift)
+	assert.Error(t, "want at least 3 fields", err)
+}
+
+func TestCSVLayers(t *testing.T) {
+	in := []save.Interval{
+		{Person: alice, Schedule: "s", StartAt: t0, EndBefore: t1, Kind: save.IntervalKindShift},
+		{Person: bob, Schedule: "s", StartAt: t0, EndBefore: t1, Kind: save.IntervalKindShift, Layer: "secondary"},
+	}
+	var buf bytes.Buffer
+	assert.Nil(t, WriteCSV(&buf, in, Column{
+		Name:  "layer",
+		Value: func(i save.Interval) string { return i.Layer },
+	}))
+	got, err := ReadCSV(&buf, "s", save.IntervalKindShift)
+	assert.Nil(t, err)
+	assert.Cmp(t, in, got)
+}
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"regexp"
 	"time"
 
 	"github.com/jreut/pager/v2/pkg/interval"
@@ -28,6 +29,9 @@ const (
 // Create an "API Integration" to get a key.
 //
 // See: https://support.atlassian.com/opsgenie/docs/create-a-default-api-integration/
+//
+// Shadow shifts are skipped unless [httpclient.ShadowRotation] names a rotation for them.
+// People are sent by name unless [httpclient.Usernames] gives their OpsGenie username.
 func NewHTTPClient(domain, key string, debug bool) httpclient {
 	return httpclient{
 		domain: domain,
@@ -41,6 +45,20 @@ func NewHTTPClient(domain, key string, debug bool) httpclient {
 type httpclient struct {
 	domain, key string
 	debug       bool
+	shadows     string
+	usernames   map[string]string
+}
+
+// ShadowRotation has Apply push shadow shifts to overrides of the named rotation, which shouldn't page anyone.
+func (c httpclient) ShadowRotation(name string) httpclient {
+	c.shadows = name
+	return c
+}
+
+// Usernames has Apply send each person's OpsGenie username from the map instead of their name.
+func (c httpclient) Usernames(m map[string]string) httpclient {
+	c.usernames = m
+	return c
 }
 
 func (c httpclient) url(path string, query url.Values) string {
@@ -52,12 +70,12 @@ func (c httpclient) url(path string, query url.Values) string {
 	}).String()
 }
 
-func (c httpclient) Post(ctx context.Context, path string, data interface{}) (*http.Response, error) {
+func (c httpclient) Post(ctx context.Context, path string, query url.Values, data interface{}) (*http.Response, error) {
 	var buf bytes.Buffer
 	if err := json.NewEncoder(&buf).Encode(data); err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequestWithContext(ctx, "POST", c.url(path, nil), &buf)
+	req, err := http.NewRequestWithContext(ctx, "POST", c.url(path, query), &buf)
 	if err != nil {
 		return nil, err
 	}
@@ -106,18 +124,35 @@ func (c httpclient) Get(ctx context.Context, path string, query url.Values) (*ht
 
 // Apply implements [./pkg/apply/cmd.Destination]
 //
+// The schedule is an ID if it looks like one, and a name otherwise.
+// Shifts outside the primary layer only override the rotation named after their layer.
+//
 // See: https://docs.opsgenie.com/docs/schedule-override-api#create-schedule-override
 func (c httpclient) Apply(ctx context.Context, schedule string, shifts []save.Interval) error {
 	for _, s := range shifts {
+		rotation := s.Layer
+		if s.Kind == save.IntervalKindShadow {
+			if c.shadows == "" {
+				continue
+			}
+			rotation = c.shadows
+		}
+		username, ok := c.usernames[s.Person]
+		if !ok {
+			username = s.Person
+		}
 		data := map[string]interface{}{
 			"user": map[string]string{
 				"type":     "user",
-				"username": s.Person,
+				"username": username,
 			},
 			"startDate": strftime(s.StartAt),
 			"endDate":   strftime(s.EndBefore),
 		}
-		res, err := c.Post(ctx, fmt.Sprintf("/v2/schedules/%s/overrides", schedule), data)
+		if rotation != save.LayerPrimary {
+			data["rotations"] = []map[string]string{{"name": rotation}}
+		}
+		res, err := c.Post(ctx, fmt.Sprintf("/v2/schedules/%s/overrides", schedule), identify(schedule, nil), data)
 		if err != nil {
 			return err
 		}
@@ -148,7 +183,7 @@ func (c httpclient) GetTimeline(ctx context.Context, schedule string, from, to t
 		strftime(from),
 		weeks,
 	)
-	res, err := c.Get(ctx, fmt.Sprintf("/v2/schedules/%s/timeline", schedule), data)
+	res, err := c.Get(ctx, fmt.Sprintf("/v2/schedules/%s/timeline", schedule), identify(schedule, data))
 	if err != nil {
 		return nil, err
 	}
@@ -170,6 +205,22 @@ func (c httpclient) GetTimeline(ctx context.Context, schedule string, from, to t
 	return interval.Flatten(intervals), nil
 }
 
+// uuid matches the IDs that OpsGenie gives schedules.
+var uuid = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// identify adds to query whether schedule is an ID or a name.
+// OpsGenie takes it for an ID unless told otherwise.
+func identify(schedule string, query url.Values) url.Values {
+	if uuid.MatchString(schedule) {
+		return query
+	}
+	if query == nil {
+		query = make(url.Values)
+	}
+	query.Set("scheduleIdentifierType", "name")
+	return query
+}
+
 func strftime(t time.Time) string {
 	return t.Format(time.RFC3339)
 }
@@ -1,12 +1,17 @@
 package og
 
 import (
+	"context"
 	_ "embed"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/jreut/pager/v2/pkg/assert"
 	"github.com/jreut/pager/v2/pkg/interval"
+	"github.com/jreut/pager/v2/pkg/save"
 )
 
 //go:embed fixtures/timeline.json
@@ -28,3 +33,54 @@ func TestTimeline(t *testing.T) {
 		interval.Flatten(parsed),
 	))
 }
+
+func TestApplyUsernames(t *testing.T) {
+	var got []string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			User struct {
+				Username string `json:"username"`
+			} `json:"user"`
+		}
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&body))
+		got = append(got, body.User.Username)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+	client := http.DefaultClient
+	http.DefaultClient = srv.Client()
+	defer func() { http.DefaultClient = client }()
+
+	start := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	shifts := []save.Interval{
+		{Person: "alice", StartAt: start, EndBefore: start.AddDate(0, 0, 7), Kind: save.IntervalKindShift},
+		{Person: "bob", StartAt: start.AddDate(0, 0, 7), EndBefore: start.AddDate(0, 0, 14), Kind: save.IntervalKindShift},
+	}
+	c := NewHTTPClient(srv.Listener.Addr().String(), "key", false).Usernames(map[string]string{"alice": "alice@example.com"})
+	assert.Nil(t, c.Apply(context.Background(), "schedule", shifts))
+	assert.Cmp(t, []string{"alice@example.com", "bob"}, got)
+}
+
+func TestApplyScheduleIdentifier(t *testing.T) {
+	var got []string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.URL.Path+"?"+r.URL.RawQuery)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+	client := http.DefaultClient
+	http.DefaultClient = srv.Client()
+	defer func() { http.DefaultClient = client }()
+
+	start := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	shifts := []save.Interval{{Person: "alice", StartAt: start, EndBefore: start.AddDate(0, 0, 7), Kind: save.IntervalKindShift}}
+	c := NewHTTPClient(srv.Listener.Addr().String(), "key", false)
+	// Names say so, and IDs go as they are.
+	for _, schedule := range []string{"platform on call", "5ef6bd2e-8f47-4a1e-9f0b-0c2a6c0b8e3d"} {
+		assert.Nil(t, c.Apply(context.Background(), schedule, shifts))
+	}
+	assert.Cmp(t, []string{
+		"/v2/schedules/platform on call/overrides?scheduleIdentifierType=name",
+		"/v2/schedules/5ef6bd2e-8f47-4a1e-9f0b-0c2a6c0b8e3d/overrides?",
+	}, got)
+}
@@ -80,7 +80,7 @@ func run(ctx context.Context) error {
 					return fmt.Errorf("unmarshaling request body: %w", err)
 				}
 			}
-			res, err := client.Post(ctx, *path, data)
+			res, err := client.Post(ctx, *path, nil, data)
 			if err != nil {
 				return fmt.Errorf("HTTP POST: %w", err)
 			}
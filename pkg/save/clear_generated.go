@@ -0,0 +1,83 @@
+package save
+
+import (
+	"context"
+	"time"
+)
+
+type ClearGeneratedParams struct {
+	Schedule  string
+	StartAt   time.Time
+	EndBefore time.Time
+}
+
+// ClearGenerated removes generated shifts and shadows from [StartAt, EndBefore), leaving manual intervals and removed ones alone.
+//
+// Generated intervals that stick out of the range are trimmed to fit around it.
+func (q *Queries) ClearGenerated(ctx context.Context, arg ClearGeneratedParams) error {
+	for _, stmt := range []struct {
+		sql  string
+		args []interface{}
+	}{
+		{
+			// Keep the tail of shifts that span the whole range.
+			sql: `
+INSERT INTO interval(person, schedule, start_at, end_before, kind, generation, layer, weight, source, author, reason, created_at, priority)
+SELECT person, schedule, ?, end_before, kind, generation, layer, weight, source, author, reason, created_at, priority
+FROM interval
+WHERE schedule = ?
+AND kind IN ('SHIFT', 'SHADOW')
+AND generation IS NOT NULL
+AND removed_at IS NULL
+AND start_at < ?
+AND end_before > ?
+`,
+			args: []interface{}{arg.EndBefore, arg.Schedule, arg.StartAt, arg.EndBefore},
+		},
+		{
+			// Keep the head of shifts that start before the range.
+			sql: `
+UPDATE interval
+SET end_before = ?
+WHERE schedule = ?
+AND kind IN ('SHIFT', 'SHADOW')
+AND generation IS NOT NULL
+AND removed_at IS NULL
+AND start_at < ?
+AND end_before > ?
+`,
+			args: []interface{}{arg.StartAt, arg.Schedule, arg.StartAt, arg.StartAt},
+		},
+		{
+			// Keep the tail of shifts that end after the range.
+			sql: `
+UPDATE interval
+SET start_at = ?
+WHERE schedule = ?
+AND kind IN ('SHIFT', 'SHADOW')
+AND generation IS NOT NULL
+AND removed_at IS NULL
+AND start_at < ?
+AND end_before > ?
+`,
+			args: []interface{}{arg.EndBefore, arg.Schedule, arg.EndBefore, arg.EndBefore},
+		},
+		{
+			sql: `
+DELETE FROM interval
+WHERE schedule = ?
+AND kind IN ('SHIFT', 'SHADOW')
+AND generation IS NOT NULL
+AND removed_at IS NULL
+AND start_at >= ?
+AND end_before <= ?
+`,
+			args: []interface{}{arg.Schedule, arg.StartAt, arg.EndBefore},
+		},
+	} {
+		if _, err := q.db.ExecContext(ctx, stmt.sql, stmt.args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package save_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/jreut/pager/v2/pkg/assert"
+	"github.com/jreut/pager/v2/pkg/save"
+)
+
+// TestClearGeneratedSplit checks that both ends of a generated shift around the cleared range keep every column.
+func TestClearGeneratedSplit(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+
+	const schedule = "schedule"
+	assert.Nil(t, q.AddSchedule(ctx, schedule))
+	assert.Nil(t, q.AddPerson(ctx, save.AddPersonParams{Name: "alice"}))
+	var (
+		t0 = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		t1 = t0.AddDate(0, 0, 1)
+		t2 = t0.AddDate(0, 0, 2)
+		t3 = t0.AddDate(0, 0, 3)
+	)
+	generation, err := q.AddGeneration(ctx, save.AddGenerationParams{Schedule: schedule, StartAt: t0, EndBefore: t3})
+	assert.Nil(t, err)
+	shift := save.AddIntervalParams{
+		Person:     "alice",
+		Schedule:   schedule,
+		StartAt:    t0,
+		EndBefore:  t3,
+		Kind:       save.IntervalKindShift,
+		Generation: sql.NullInt64{Int64: generation, Valid: true},
+		Layer:      "secondary",
+		Weight:     0.5,
+		Source:     save.IntervalSourceGenerate,
+		Author:     "bob",
+		Reason:     "why not",
+		CreatedAt:  t0,
+		Priority:   1,
+	}
+	assert.Nil(t, q.AddLayer(ctx, save.AddLayerParams{Schedule: schedule, Name: shift.Layer}))
+	assert.Nil(t, q.AddInterval(ctx, shift))
+	assert.Nil(t, q.ClearGenerated(ctx, save.ClearGeneratedParams{Schedule: schedule, StartAt: t1, EndBefore: t2}))
+
+	got, err := q.ListIntervals(ctx, save.ListIntervalsParams{
+		Schedule:  schedule,
+		Kind:      save.IntervalKindShift,
+		StartAt:   t0,
+		EndBefore: t3,
+	})
+	assert.Nil(t, err)
+	var params []save.AddIntervalParams
+	for _, i := range got {
+		params = append(params, i.Params())
+	}
+	head, tail := shift, shift
+	head.EndBefore, tail.StartAt = t1, t2
+	assert.Cmp(t, []save.AddIntervalParams{head, tail}, params)
+}
@@ -0,0 +1,10 @@
+package save
+
+import "testing"
+
+// SetBusyRetries has [WithTx] try n times to begin a transaction until the test ends.
+func SetBusyRetries(t testing.TB, n int) {
+	old := busyRetries
+	busyRetries = n
+	t.Cleanup(func() { busyRetries = old })
+}
@@ -12,19 +12,46 @@ type ListIntervalsParams struct {
 	EndBefore time.Time
 }
 
+// ListIntervals lists intervals that haven't been removed in the order that [interval.Flatten] expects.
+//
+// Intervals with a higher priority come after those with a lower one, so they override them regardless of when they were added.
+// Among intervals with the same priority, manual intervals come after generated ones, in the order they were added, so they override them.
+// Each generation comes after the ones before it.
+// Within a generation, shorter intervals come after the longer ones that contain them, so covers override the shifts they cover.
+// The order they were added is the order of ID, which only ever increases.
+// Unlike created_at, it doesn't depend on the clocks of whoever shares the database, nor on how the database happens to store the rows.
 func (q *Queries) ListIntervals(ctx context.Context, arg ListIntervalsParams) ([]Interval, error) {
 	const sql = `
 SELECT
-  person
+  id
+, person
 , schedule
 , start_at
 , end_before
 , kind
+, generation
+, layer
+, weight
+, source
+, author
+, reason
+, created_at
+, priority
+, removed_at
+, removed_by
 FROM interval
 WHERE schedule = ?
 AND kind = ?
 AND start_at < ?
 AND end_before > ?
+AND removed_at IS NULL
+ORDER BY
+  priority
+, generation IS NULL
+, generation
+, CASE WHEN generation IS NOT NULL THEN start_at END
+, CASE WHEN generation IS NOT NULL THEN end_before END DESC
+, id
 `
 	rows, err := q.db.QueryContext(ctx, sql, arg.Schedule, arg.Kind, arg.EndBefore, arg.StartAt)
 	if err != nil {
@@ -35,11 +62,22 @@ AND end_before > ?
 	for rows.Next() {
 		var i Interval
 		if err := rows.Scan(
+			&i.ID,
 			&i.Person,
 			&i.Schedule,
 			&i.StartAt,
 			&i.EndBefore,
 			&i.Kind,
+			&i.Generation,
+			&i.Layer,
+			&i.Weight,
+			&i.Source,
+			&i.Author,
+			&i.Reason,
+			&i.CreatedAt,
+			&i.Priority,
+			&i.RemovedAt,
+			&i.RemovedBy,
 		); err != nil {
 			return nil, err
 		}
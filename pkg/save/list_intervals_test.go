@@ -2,6 +2,7 @@ package save_test
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -40,6 +41,9 @@ func TestListIntervals(t *testing.T) {
 		georgia = "georgia"
 		helen   = "helen"
 	)
+	for _, p := range []string{alice, bob, cindy, daria, evan, felix, georgia, helen} {
+		assert.Nil(t, q.AddPerson(ctx, save.AddPersonParams{Name: p}))
+	}
 
 	before := []save.AddIntervalParams{
 		// t0 t1 t2 t3 t4 t5 t6 t7
@@ -88,10 +92,86 @@ func TestListIntervals(t *testing.T) {
 		})
 		assert.Nil(t, err)
 		assert.Cmp(t, []save.Interval{
-			{Person: cindy, Schedule: s1, StartAt: t1, EndBefore: t4, Kind: save.IntervalKindShift},
-			{Person: daria, Schedule: s1, StartAt: t3, EndBefore: t6, Kind: save.IntervalKindShift},
-			{Person: georgia, Schedule: s1, StartAt: t2, EndBefore: t3, Kind: save.IntervalKindShift},
-			{Schedule: s1, StartAt: t4, EndBefore: t5, Kind: save.IntervalKindShift, Person: helen},
+			{ID: 3, Person: cindy, Schedule: s1, StartAt: t1, EndBefore: t4, Kind: save.IntervalKindShift},
+			{ID: 4, Person: daria, Schedule: s1, StartAt: t3, EndBefore: t6, Kind: save.IntervalKindShift},
+			{ID: 7, Person: georgia, Schedule: s1, StartAt: t2, EndBefore: t3, Kind: save.IntervalKindShift},
+			{ID: 8, Schedule: s1, StartAt: t4, EndBefore: t5, Kind: save.IntervalKindShift, Person: helen},
 		}, got)
 	}
 }
+
+func TestListIntervalsInsertionOrder(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+
+	const schedule = "schedule"
+	assert.Nil(t, q.AddSchedule(ctx, schedule))
+	for _, p := range []string{"alice", "bob"} {
+		assert.Nil(t, q.AddPerson(ctx, save.AddPersonParams{Name: p}))
+	}
+	var (
+		t0 = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		t1 = t0.AddDate(0, 0, 1)
+	)
+	// Alice's override is added after bob's, but by someone whose clock runs behind, so it looks created earlier.
+	// It still overrides bob's.
+	for _, i := range []save.AddIntervalParams{
+		{Person: "bob", CreatedAt: t1},
+		{Person: "alice", CreatedAt: t0},
+	} {
+		i.Schedule, i.StartAt, i.EndBefore, i.Kind, i.Source = schedule, t0, t1, save.IntervalKindShift, save.IntervalSourceManual
+		assert.Nil(t, q.AddInterval(ctx, i))
+	}
+	got, err := q.ListIntervals(ctx, save.ListIntervalsParams{
+		Schedule:  schedule,
+		Kind:      save.IntervalKindShift,
+		StartAt:   t0,
+		EndBefore: t1,
+	})
+	assert.Nil(t, err)
+	var people []string
+	for _, i := range got {
+		people = append(people, i.Person)
+	}
+	assert.Cmp(t, []string{"bob", "alice"}, people)
+}
+
+func TestListIntervalsPriority(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+
+	const schedule = "schedule"
+	assert.Nil(t, q.AddSchedule(ctx, schedule))
+	for _, p := range []string{"alice", "bob", "cindy"} {
+		assert.Nil(t, q.AddPerson(ctx, save.AddPersonParams{Name: p}))
+	}
+	var (
+		t0 = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		t1 = t0.AddDate(0, 0, 1)
+	)
+	// Alice's shift has priority, so it beats the manual shifts added after it.
+	for _, i := range []save.AddIntervalParams{
+		{Person: "alice", Priority: 1, Generation: sql.NullInt64{Int64: 1, Valid: true}},
+		{Person: "bob"},
+		{Person: "cindy", Priority: -1},
+	} {
+		i.Schedule, i.StartAt, i.EndBefore, i.Kind = schedule, t0, t1, save.IntervalKindShift
+		if i.Generation.Valid {
+			_, err := q.AddGeneration(ctx, save.AddGenerationParams{Schedule: schedule, StartAt: t0, EndBefore: t1})
+			assert.Nil(t, err)
+		}
+		assert.Nil(t, q.AddInterval(ctx, i))
+	}
+	got, err := q.ListIntervals(ctx, save.ListIntervalsParams{
+		Schedule:  schedule,
+		Kind:      save.IntervalKindShift,
+		StartAt:   t0,
+		EndBefore: t1,
+	})
+	assert.Nil(t, err)
+	var people []string
+	for _, i := range got {
+		people = append(people, i.Person)
+	}
+	assert.Cmp(t, []string{"cindy", "bob", "alice"}, people)
+}
@@ -0,0 +1,161 @@
+package save
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Each dialect has its own migrations, named after the version they bring the schema to, like 0002_people.sql.
+// Every dialect has the same number of them.
+// Schema.sql has to match what the SQLite migrations make together, along with its version.
+//
+//go:embed migrations/*/*.sql
+var migrationFS embed.FS
+
+var migrations = map[dialect][]string{
+	sqlite:   load(sqlite),
+	postgres: load(postgres),
+}
+
+func load(d dialect) []string {
+	dir := path.Join("migrations", string(d))
+	names, err := migrationFS.ReadDir(dir)
+	if err != nil {
+		panic(err)
+	}
+	var out []string
+	sort.Slice(names, func(i, j int) bool { return names[i].Name() < names[j].Name() })
+	for i, n := range names {
+		prefix, _, _ := strings.Cut(n.Name(), "_")
+		if v, err := strconv.Atoi(prefix); err != nil || v != i+1 {
+			panic(fmt.Sprintf("migration %s/%s is out of order: want version %d", d, n.Name(), i+1))
+		}
+		b, err := migrationFS.ReadFile(path.Join(dir, n.Name()))
+		if err != nil {
+			panic(err)
+		}
+		out = append(out, string(b))
+	}
+	return out
+}
+
+// Latest is the version of the schema that this binary uses.
+var Latest = len(migrations[sqlite])
+
+func init() {
+	if n := len(migrations[postgres]); n != Latest {
+		panic(fmt.Sprintf("%d PostgreSQL migrations don't match %d SQLite migrations", n, Latest))
+	}
+}
+
+var (
+	// ErrOutdated means the database needs [Migrate] before this binary can use it.
+	ErrOutdated = errors.New("database needs migrating")
+	// ErrTooNew means a newer binary migrated the database past what this one knows about.
+	ErrTooNew = errors.New("database is newer than this binary")
+)
+
+const createSchemaVersion = `CREATE TABLE schema_version
+( version INTEGER NOT NULL
+);
+`
+
+// Version returns the version of the database's schema.
+// An empty database is at version 0.
+// A database from before versions were recorded is at version 1, the first release.
+func Version(ctx context.Context, db *sql.DB) (int, error) {
+	return version(ctx, db, dialectOf(db))
+}
+
+func version(ctx context.Context, db DBTX, d dialect) (int, error) {
+	tables := `
+SELECT count(*), count(*) FILTER (WHERE name = 'schema_version')
+FROM sqlite_master
+WHERE type = 'table'
+AND name NOT LIKE 'sqlite_%'
+`
+	if d == postgres {
+		tables = `
+SELECT count(*), count(*) FILTER (WHERE table_name = 'schema_version')
+FROM information_schema.tables
+WHERE table_schema = current_schema()
+AND table_type = 'BASE TABLE'
+`
+	}
+	var n, versioned int
+	if err := db.QueryRowContext(ctx, tables).Scan(&n, &versioned); err != nil {
+		return 0, fmt.Errorf("reading schema version: %w", err)
+	}
+	switch {
+	case n == 0:
+		return 0, nil
+	case versioned == 0:
+		return 1, nil
+	}
+	var v int
+	if err := db.QueryRowContext(ctx, `SELECT max(version) FROM schema_version`).Scan(&v); err != nil {
+		return 0, fmt.Errorf("reading schema version: %w", err)
+	}
+	return v, nil
+}
+
+func check(ctx context.Context, db *sql.DB) error {
+	v, err := Version(ctx, db)
+	if err != nil {
+		return err
+	}
+	switch {
+	case v > Latest:
+		return fmt.Errorf("%w: schema version %d is past %d: upgrade pager", ErrTooNew, v, Latest)
+	case v == 0:
+		return fmt.Errorf("%w: database is empty: run migrate to create it", ErrOutdated)
+	case v < Latest:
+		return fmt.Errorf("%w: schema version %d is behind %d: run migrate", ErrOutdated, v, Latest)
+	}
+	return nil
+}
+
+// Migrate brings the database's schema up to the [Latest] version in one transaction, returning the versions it went from and to.
+// It refuses to touch a database that a newer binary migrated.
+func Migrate(ctx context.Context, db *sql.DB) (from, to int, err error) {
+	d := dialectOf(db)
+	err = WithTx(db, func(tx *sql.Tx) error {
+		from, err = version(ctx, tx, d)
+		if err != nil {
+			return err
+		}
+		if from > Latest {
+			return fmt.Errorf("%w: schema version %d is past %d: upgrade pager", ErrTooNew, from, Latest)
+		}
+		if from == Latest {
+			return nil
+		}
+		// Versions were first recorded at version 2.
+		if from < 2 {
+			if _, err := tx.ExecContext(ctx, createSchemaVersion); err != nil {
+				return err
+			}
+		}
+		for v := from + 1; v <= Latest; v++ {
+			if _, err := tx.ExecContext(ctx, migrations[d][v-1]); err != nil {
+				return fmt.Errorf("migrating to version %d: %w", v, err)
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM schema_version`); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `INSERT INTO schema_version(version) VALUES (?)`, Latest)
+		return err
+	})
+	if err != nil {
+		return from, from, err
+	}
+	return from, Latest, nil
+}
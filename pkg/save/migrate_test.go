@@ -0,0 +1,138 @@
+package save_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jreut/pager/v2/pkg/assert"
+	"github.com/jreut/pager/v2/pkg/save"
+	"github.com/jreut/pager/v2/pkg/save/savetest"
+)
+
+// TestMigrate checks that the migrations make the same schema as schema.sql.
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	want := savetest.SQLite(t, ctx)
+
+	got, err := save.OpenUnchecked(filepath.Join(t.TempDir(), "db.sqlite3"), nil)
+	assert.Nil(t, err)
+	defer got.Close()
+	from, to, err := save.Migrate(ctx, got)
+	assert.Nil(t, err)
+	assert.Cmp(t, 0, from)
+	assert.Cmp(t, save.Latest, to)
+
+	assert.Cmp(t, schema(t, want), schema(t, got))
+	v, err := save.Version(ctx, want)
+	assert.Nil(t, err)
+	assert.Cmp(t, save.Latest, v)
+
+	// Migrating again does nothing.
+	from, to, err = save.Migrate(ctx, got)
+	assert.Nil(t, err)
+	assert.Cmp(t, save.Latest, from)
+	assert.Cmp(t, save.Latest, to)
+}
+
+func schema(t *testing.T, db *sql.DB) [][4]string {
+	t.Helper()
+	rows, err := db.Query(`
+SELECT type, name, tbl_name, coalesce(sql, '')
+FROM sqlite_master
+ORDER BY type, name
+`)
+	assert.Nil(t, err)
+	defer rows.Close()
+	var out [][4]string
+	for rows.Next() {
+		var row [4]string
+		assert.Nil(t, rows.Scan(&row[0], &row[1], &row[2], &row[3]))
+		out = append(out, row)
+	}
+	assert.Nil(t, rows.Err())
+	return out
+}
+
+// TestMigrateFirstRelease upgrades a database made before versions were recorded.
+func TestMigrateFirstRelease(t *testing.T) {
+	ctx := context.Background()
+	db, err := save.OpenUnchecked(filepath.Join(t.TempDir(), "db.sqlite3"), nil)
+	assert.Nil(t, err)
+	defer db.Close()
+	first, err := os.ReadFile("migrations/sqlite/0001_init.sql")
+	assert.Nil(t, err)
+	_, err = db.ExecContext(ctx, string(first))
+	assert.Nil(t, err)
+	t0 := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, stmt := range []string{
+		`INSERT INTO schedule(name) VALUES ('default')`,
+		`INSERT INTO event(person, schedule, kind, at) VALUES ('alice', 'default', 'ADD', ?1), ('bob', 'default', 'ADD', ?1)`,
+		`INSERT INTO interval(person, schedule, start_at, end_before, kind) VALUES ('bob', 'default', ?1, ?2, 'SHIFT'), ('alice', 'default', ?1, ?2, 'EXCLUSION')`,
+	} {
+		_, err := db.ExecContext(ctx, stmt, t0, t0.AddDate(0, 0, 7))
+		assert.Nil(t, err)
+	}
+
+	v, err := save.Version(ctx, db)
+	assert.Nil(t, err)
+	assert.Cmp(t, 1, v)
+	from, to, err := save.Migrate(ctx, db)
+	assert.Nil(t, err)
+	assert.Cmp(t, 1, from)
+	assert.Cmp(t, save.Latest, to)
+
+	q := save.New(db)
+	people, err := q.ListPeople(ctx)
+	assert.Nil(t, err)
+	assert.Cmp(t, []save.Person{{Name: "alice"}, {Name: "bob"}}, people)
+	events, err := q.ListEvents(ctx, "default")
+	assert.Nil(t, err)
+	assert.Cmp(t, 2, len(events))
+	got, err := q.GetInterval(ctx, 2)
+	assert.Nil(t, err)
+	assert.Cmp(t, save.Interval{
+		ID:        2,
+		Person:    "alice",
+		Schedule:  "default",
+		StartAt:   t0,
+		EndBefore: t0.AddDate(0, 0, 7),
+		Kind:      save.IntervalKindExclusion,
+		CreatedAt: time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, got)
+}
+
+func TestOpen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "db.sqlite3")
+
+	_, err := save.Open(path, nil)
+	assert.Error(t, "database is empty: run migrate", err)
+	if !errors.Is(err, save.ErrOutdated) {
+		t.Fatalf("want save.ErrOutdated, got %v", err)
+	}
+
+	db, err := save.OpenUnchecked(path, nil)
+	assert.Nil(t, err)
+	defer db.Close()
+	_, _, err = save.Migrate(ctx, db)
+	assert.Nil(t, err)
+	ok, err := save.Open(path, nil)
+	assert.Nil(t, err)
+	ok.Close()
+
+	_, err = db.ExecContext(ctx, `UPDATE schema_version SET version = version + 1`)
+	assert.Nil(t, err)
+	_, err = save.Open(path, nil)
+	if !errors.Is(err, save.ErrTooNew) {
+		t.Fatalf("want save.ErrTooNew, got %v", err)
+	}
+	_, _, err = save.Migrate(ctx, db)
+	if !errors.Is(err, save.ErrTooNew) {
+		t.Fatalf("want save.ErrTooNew, got %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+-- PostgreSQL has no rowid, so every table has one of its own.
+-- Queries order by it and the history points at rows with it, just like in SQLite.
+CREATE TABLE schedule
+( rowid BIGSERIAL UNIQUE
+, name TEXT PRIMARY KEY
+, CHECK ( name != '' )
+);
+CREATE TABLE event
+( rowid BIGSERIAL UNIQUE
+, person TEXT NOT NULL
+, schedule TEXT NOT NULL
+, kind TEXT NOT NULL
+, at TIMESTAMPTZ NOT NULL
+, FOREIGN KEY (schedule) REFERENCES schedule(name)
+, CHECK ( person != '' )
+, CONSTRAINT event_kind CHECK ( kind IN
+		('ADD'
+		,'REMOVE'
+		)
+	)
+);
+CREATE TABLE interval
+( rowid BIGSERIAL UNIQUE
+, person TEXT NOT NULL
+, schedule TEXT NOT NULL
+, start_at TIMESTAMPTZ NOT NULL
+, end_before TIMESTAMPTZ NOT NULL
+, kind TEXT NOT NULL
+, FOREIGN KEY (schedule) REFERENCES schedule(name)
+, CHECK ( person != '' )
+, CHECK ( start_at < end_before )
+, CONSTRAINT interval_kind CHECK ( kind IN
+		('SHIFT'
+		,'EXCLUSION'
+		)
+	)
+);
@@ -0,0 +1,206 @@
+CREATE TABLE person
+( rowid BIGSERIAL UNIQUE
+, name TEXT PRIMARY KEY
+, display_name TEXT NOT NULL DEFAULT ''
+, email TEXT NOT NULL DEFAULT ''
+, timezone TEXT NOT NULL DEFAULT ''
+, CHECK ( name != '' )
+);
+INSERT INTO person(name)
+SELECT person FROM event
+UNION
+SELECT person FROM interval
+ORDER BY 1;
+CREATE TABLE identity
+( rowid BIGSERIAL UNIQUE
+, person TEXT NOT NULL
+, destination TEXT NOT NULL
+, name TEXT NOT NULL
+, FOREIGN KEY (person) REFERENCES person(name)
+, UNIQUE (person, destination)
+, CHECK ( destination != '' )
+, CHECK ( name != '' )
+);
+ALTER TABLE event
+	ADD FOREIGN KEY (person) REFERENCES person(name),
+	DROP CONSTRAINT event_kind,
+	ADD CONSTRAINT event_kind CHECK ( kind IN
+		('ADD'
+		,'REMOVE'
+		,'SHADOW'
+		)
+	);
+CREATE TABLE generation
+( rowid BIGSERIAL UNIQUE
+, id BIGSERIAL PRIMARY KEY
+, schedule TEXT NOT NULL
+, style TEXT NOT NULL
+, params TEXT NOT NULL
+, start_at TIMESTAMPTZ NOT NULL
+, end_before TIMESTAMPTZ NOT NULL
+, seed BIGINT NOT NULL DEFAULT 0
+, FOREIGN KEY (schedule) REFERENCES schedule(name)
+, CHECK ( start_at < end_before )
+);
+-- Existing intervals take their IDs in the order they were added, like SQLite's rowid.
+ALTER TABLE interval
+	ADD COLUMN id BIGINT,
+	ADD COLUMN generation BIGINT REFERENCES generation(id),
+	ADD COLUMN layer TEXT NOT NULL DEFAULT '',
+	ADD COLUMN weight DOUBLE PRECISION NOT NULL DEFAULT 0,
+	ADD COLUMN source TEXT NOT NULL DEFAULT '',
+	ADD COLUMN author TEXT NOT NULL DEFAULT '',
+	ADD COLUMN reason TEXT NOT NULL DEFAULT '',
+	ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT '0001-01-01 00:00:00+00:00',
+	ADD COLUMN priority BIGINT NOT NULL DEFAULT 0,
+	ADD COLUMN removed_at TIMESTAMPTZ,
+	ADD COLUMN removed_by TEXT NOT NULL DEFAULT '',
+	ADD FOREIGN KEY (person) REFERENCES person(name),
+	DROP CONSTRAINT interval_kind,
+	ADD CONSTRAINT interval_kind CHECK ( kind IN
+		('SHIFT'
+		,'EXCLUSION'
+		,'SHADOW'
+		,'PREFERENCE'
+		)
+	),
+	ADD CHECK ( source IN
+		(''
+		,'MANUAL'
+		,'GENERATE'
+		,'IMPORT'
+		)
+	);
+UPDATE interval SET id = rowid;
+CREATE SEQUENCE interval_id_seq OWNED BY interval.id;
+SELECT setval('interval_id_seq', coalesce(max(id), 0) + 1, false) FROM interval;
+ALTER TABLE interval
+	ALTER COLUMN id SET DEFAULT nextval('interval_id_seq'),
+	ADD PRIMARY KEY (id);
+CREATE TABLE tag
+( rowid BIGSERIAL UNIQUE
+, person TEXT NOT NULL
+, schedule TEXT NOT NULL
+, tag TEXT NOT NULL
+, FOREIGN KEY (person) REFERENCES person(name)
+, FOREIGN KEY (schedule) REFERENCES schedule(name)
+, UNIQUE (person, schedule, tag)
+, CHECK ( person != '' )
+, CHECK ( tag != '' )
+);
+CREATE TABLE holiday
+( rowid BIGSERIAL UNIQUE
+, schedule TEXT NOT NULL
+, name TEXT NOT NULL
+, start_at TIMESTAMPTZ NOT NULL
+, end_before TIMESTAMPTZ NOT NULL
+, FOREIGN KEY (schedule) REFERENCES schedule(name)
+, UNIQUE (schedule, start_at)
+, CHECK ( name != '' )
+, CHECK ( start_at < end_before )
+);
+CREATE TABLE constraints
+( rowid BIGSERIAL UNIQUE
+, schedule TEXT PRIMARY KEY
+, min_rest_seconds BIGINT NOT NULL DEFAULT 0
+, max_consecutive BIGINT NOT NULL DEFAULT 0
+, max_seconds_per_30_days BIGINT NOT NULL DEFAULT 0
+, FOREIGN KEY (schedule) REFERENCES schedule(name)
+, CHECK ( min_rest_seconds >= 0 )
+, CHECK ( max_consecutive >= 0 )
+, CHECK ( max_seconds_per_30_days >= 0 )
+);
+CREATE TABLE layer
+( rowid BIGSERIAL UNIQUE
+, schedule TEXT NOT NULL
+, name TEXT NOT NULL
+, FOREIGN KEY (schedule) REFERENCES schedule(name)
+, UNIQUE (schedule, name)
+, CHECK ( name != '' )
+);
+CREATE TABLE requirement
+( rowid BIGSERIAL UNIQUE
+, schedule TEXT NOT NULL
+, tag TEXT NOT NULL
+, at_least BIGINT NOT NULL DEFAULT 0
+, at_most BIGINT NOT NULL DEFAULT -1
+, FOREIGN KEY (schedule) REFERENCES schedule(name)
+, UNIQUE (schedule, tag)
+, CHECK ( tag != '' )
+, CHECK ( at_least >= 0 )
+, CHECK ( at_most >= -1 )
+);
+CREATE TABLE changeset
+( id BIGSERIAL PRIMARY KEY
+, command TEXT NOT NULL
+, args TEXT NOT NULL DEFAULT '[]'
+, author TEXT NOT NULL DEFAULT ''
+, at TIMESTAMPTZ NOT NULL
+, open BOOLEAN NOT NULL DEFAULT FALSE
+, undoes BIGINT
+, FOREIGN KEY (undoes) REFERENCES changeset(id)
+, CHECK ( command != '' )
+);
+CREATE INDEX changeset_open ON changeset(id) WHERE open;
+CREATE TABLE change
+( id BIGSERIAL PRIMARY KEY
+, changeset BIGINT NOT NULL
+, table_name TEXT NOT NULL
+, op TEXT NOT NULL
+, row_id BIGINT NOT NULL
+, old TEXT
+, new TEXT
+, FOREIGN KEY (changeset) REFERENCES changeset(id)
+, CHECK ( op IN
+		('INSERT'
+		,'UPDATE'
+		,'DELETE'
+		)
+	)
+);
+CREATE INDEX change_row ON change(table_name, row_id);
+-- One function records the changes to every table, as the SQLite triggers do, leaving out the rowid that row_id already holds.
+CREATE FUNCTION record_change() RETURNS trigger AS $$
+DECLARE
+	old_row TEXT;
+	new_row TEXT;
+	rid BIGINT;
+BEGIN
+	IF NOT EXISTS (SELECT 1 FROM changeset WHERE open) THEN
+		RETURN NULL;
+	END IF;
+	IF TG_OP != 'INSERT' THEN
+		old_row := (to_jsonb(OLD) - 'rowid')::text;
+		rid := OLD.rowid;
+	END IF;
+	IF TG_OP != 'DELETE' THEN
+		new_row := (to_jsonb(NEW) - 'rowid')::text;
+		rid := NEW.rowid;
+	END IF;
+	INSERT INTO change(changeset, table_name, op, row_id, old, new)
+	SELECT id, TG_TABLE_NAME, TG_OP, rid, old_row, new_row FROM changeset WHERE open;
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+CREATE TRIGGER schedule_change AFTER INSERT OR UPDATE OR DELETE ON schedule
+FOR EACH ROW EXECUTE FUNCTION record_change();
+CREATE TRIGGER person_change AFTER INSERT OR UPDATE OR DELETE ON person
+FOR EACH ROW EXECUTE FUNCTION record_change();
+CREATE TRIGGER identity_change AFTER INSERT OR UPDATE OR DELETE ON identity
+FOR EACH ROW EXECUTE FUNCTION record_change();
+CREATE TRIGGER event_change AFTER INSERT OR UPDATE OR DELETE ON event
+FOR EACH ROW EXECUTE FUNCTION record_change();
+CREATE TRIGGER interval_change AFTER INSERT OR UPDATE OR DELETE ON interval
+FOR EACH ROW EXECUTE FUNCTION record_change();
+CREATE TRIGGER tag_change AFTER INSERT OR UPDATE OR DELETE ON tag
+FOR EACH ROW EXECUTE FUNCTION record_change();
+CREATE TRIGGER holiday_change AFTER INSERT OR UPDATE OR DELETE ON holiday
+FOR EACH ROW EXECUTE FUNCTION record_change();
+CREATE TRIGGER constraints_change AFTER INSERT OR UPDATE OR DELETE ON constraints
+FOR EACH ROW EXECUTE FUNCTION record_change();
+CREATE TRIGGER generation_change AFTER INSERT OR UPDATE OR DELETE ON generation
+FOR EACH ROW EXECUTE FUNCTION record_change();
+CREATE TRIGGER layer_change AFTER INSERT OR UPDATE OR DELETE ON layer
+FOR EACH ROW EXECUTE FUNCTION record_change();
+CREATE TRIGGER requirement_change AFTER INSERT OR UPDATE OR DELETE ON requirement
+FOR EACH ROW EXECUTE FUNCTION record_change();
@@ -0,0 +1,24 @@
+-- Settings are what commands that take a schedule fall back on when they aren't told otherwise.
+CREATE TABLE settings
+( rowid BIGSERIAL UNIQUE
+, schedule TEXT PRIMARY KEY
+, timezone TEXT NOT NULL DEFAULT ''
+, description TEXT NOT NULL DEFAULT ''
+, style TEXT NOT NULL DEFAULT ''
+, params TEXT NOT NULL DEFAULT ''
+, FOREIGN KEY (schedule) REFERENCES schedule(name)
+);
+CREATE TABLE remote_schedule
+( rowid BIGSERIAL UNIQUE
+, schedule TEXT NOT NULL
+, destination TEXT NOT NULL
+, name TEXT NOT NULL
+, FOREIGN KEY (schedule) REFERENCES schedule(name)
+, UNIQUE (schedule, destination)
+, CHECK ( destination != '' )
+, CHECK ( name != '' )
+);
+CREATE TRIGGER settings_change AFTER INSERT OR UPDATE OR DELETE ON settings
+FOR EACH ROW EXECUTE FUNCTION record_change();
+CREATE TRIGGER remote_schedule_change AFTER INSERT OR UPDATE OR DELETE ON remote_schedule
+FOR EACH ROW EXECUTE FUNCTION record_change();
@@ -0,0 +1,2 @@
+-- Archived schedules are hidden from list-schedules, and only they can be deleted.
+ALTER TABLE schedule ADD COLUMN archived_at TIMESTAMPTZ;
@@ -0,0 +1,8 @@
+-- Layers keep their order in a column of their own instead of relying on how the database stores them.
+ALTER TABLE layer ADD COLUMN position INTEGER NOT NULL DEFAULT 0;
+UPDATE layer SET position =
+( SELECT count(*) FROM layer AS earlier
+  WHERE earlier.schedule = layer.schedule
+  AND earlier.rowid <= layer.rowid
+);
+CREATE UNIQUE INDEX layer_position ON layer(schedule, position);
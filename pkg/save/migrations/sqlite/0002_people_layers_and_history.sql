@@ -0,0 +1,372 @@
+-- Upgrade the first release to the schema with people, layers, generations and history.
+--
+-- Everyone named by an event or interval becomes a person.
+-- Event and interval gain checks and foreign keys, so they are rebuilt, keeping their rows in order.
+-- Earlier intervals keep their rowid as their ID, and get an unknown source.
+CREATE TABLE person
+( name TEXT PRIMARY KEY
+, display_name TEXT NOT NULL DEFAULT ''
+, email TEXT NOT NULL DEFAULT ''
+, timezone TEXT NOT NULL DEFAULT ''
+, CHECK ( name != '' )
+);
+INSERT INTO person(name)
+SELECT person FROM event
+UNION
+SELECT person FROM interval;
+CREATE TABLE identity
+( person TEXT NOT NULL
+, destination TEXT NOT NULL
+, name TEXT NOT NULL
+, FOREIGN KEY (person) REFERENCES person(name)
+, UNIQUE (person, destination)
+, CHECK ( destination != '' )
+, CHECK ( name != '' )
+);
+ALTER TABLE event RENAME TO event_old;
+CREATE TABLE event
+( person TEXT NOT NULL
+, schedule TEXT NOT NULL
+, kind TEXT NOT NULL
+, at TIMESTAMP NOT NULL
+, FOREIGN KEY (person) REFERENCES person(name)
+, FOREIGN KEY (schedule) REFERENCES schedule(name)
+, CHECK ( person != '' )
+, CHECK ( kind IN
+		('ADD'
+		,'REMOVE'
+		,'SHADOW'
+		)
+	)
+);
+INSERT INTO event(rowid, person, schedule, kind, at)
+SELECT rowid, person, schedule, kind, at FROM event_old;
+DROP TABLE event_old;
+CREATE TABLE generation
+( id INTEGER PRIMARY KEY
+, schedule TEXT NOT NULL
+, style TEXT NOT NULL
+, params TEXT NOT NULL
+, start_at TIMESTAMP NOT NULL
+, end_before TIMESTAMP NOT NULL
+, seed INTEGER NOT NULL DEFAULT 0
+, FOREIGN KEY (schedule) REFERENCES schedule(name)
+, CHECK ( start_at < end_before )
+);
+ALTER TABLE interval RENAME TO interval_old;
+CREATE TABLE interval
+( id INTEGER PRIMARY KEY AUTOINCREMENT
+, person TEXT NOT NULL
+, schedule TEXT NOT NULL
+, start_at TIMESTAMP NOT NULL
+, end_before TIMESTAMP NOT NULL
+, kind TEXT NOT NULL
+, generation INTEGER
+, layer TEXT NOT NULL DEFAULT ''
+, weight REAL NOT NULL DEFAULT 0
+, source TEXT NOT NULL DEFAULT ''
+, author TEXT NOT NULL DEFAULT ''
+, reason TEXT NOT NULL DEFAULT ''
+, created_at TIMESTAMP NOT NULL DEFAULT '0001-01-01 00:00:00+00:00'
+, priority INTEGER NOT NULL DEFAULT 0
+, removed_at TIMESTAMP
+, removed_by TEXT NOT NULL DEFAULT ''
+, FOREIGN KEY (person) REFERENCES person(name)
+, FOREIGN KEY (schedule) REFERENCES schedule(name)
+, FOREIGN KEY (generation) REFERENCES generation(id)
+, CHECK ( person != '' )
+, CHECK ( start_at < end_before )
+, CHECK ( kind IN
+		('SHIFT'
+		,'EXCLUSION'
+		,'SHADOW'
+		,'PREFERENCE'
+		)
+	)
+, CHECK ( source IN
+		(''
+		,'MANUAL'
+		,'GENERATE'
+		,'IMPORT'
+		)
+	)
+);
+INSERT INTO interval(id, person, schedule, start_at, end_before, kind)
+SELECT rowid, person, schedule, start_at, end_before, kind FROM interval_old;
+DROP TABLE interval_old;
+CREATE TABLE tag
+( person TEXT NOT NULL
+, schedule TEXT NOT NULL
+, tag TEXT NOT NULL
+, FOREIGN KEY (person) REFERENCES person(name)
+, FOREIGN KEY (schedule) REFERENCES schedule(name)
+, UNIQUE (person, schedule, tag)
+, CHECK ( person != '' )
+, CHECK ( tag != '' )
+);
+CREATE TABLE holiday
+( schedule TEXT NOT NULL
+, name TEXT NOT NULL
+, start_at TIMESTAMP NOT NULL
+, end_before TIMESTAMP NOT NULL
+, FOREIGN KEY (schedule) REFERENCES schedule(name)
+, UNIQUE (schedule, start_at)
+, CHECK ( name != '' )
+, CHECK ( start_at < end_before )
+);
+CREATE TABLE constraints
+( schedule TEXT PRIMARY KEY
+, min_rest_seconds INTEGER NOT NULL DEFAULT 0
+, max_consecutive INTEGER NOT NULL DEFAULT 0
+, max_seconds_per_30_days INTEGER NOT NULL DEFAULT 0
+, FOREIGN KEY (schedule) REFERENCES schedule(name)
+, CHECK ( min_rest_seconds >= 0 )
+, CHECK ( max_consecutive >= 0 )
+, CHECK ( max_seconds_per_30_days >= 0 )
+);
+CREATE TABLE layer
+( schedule TEXT NOT NULL
+, name TEXT NOT NULL
+, FOREIGN KEY (schedule) REFERENCES schedule(name)
+, UNIQUE (schedule, name)
+, CHECK ( name != '' )
+);
+CREATE TABLE requirement
+( schedule TEXT NOT NULL
+, tag TEXT NOT NULL
+, at_least INTEGER NOT NULL DEFAULT 0
+, at_most INTEGER NOT NULL DEFAULT -1
+, FOREIGN KEY (schedule) REFERENCES schedule(name)
+, UNIQUE (schedule, tag)
+, CHECK ( tag != '' )
+, CHECK ( at_least >= 0 )
+, CHECK ( at_most >= -1 )
+);
+-- Each command that changes anything opens a changeset, and triggers on the other tables record each row they touch in it.
+CREATE TABLE changeset
+( id INTEGER PRIMARY KEY
+, command TEXT NOT NULL
+, args TEXT NOT NULL DEFAULT '[]'
+, author TEXT NOT NULL DEFAULT ''
+, at TIMESTAMP NOT NULL
+, open BOOLEAN NOT NULL DEFAULT FALSE
+, undoes INTEGER
+, FOREIGN KEY (undoes) REFERENCES changeset(id)
+, CHECK ( command != '' )
+);
+CREATE INDEX changeset_open ON changeset(id) WHERE open;
+CREATE TABLE change
+( id INTEGER PRIMARY KEY
+, changeset INTEGER NOT NULL
+, table_name TEXT NOT NULL
+, op TEXT NOT NULL
+, row_id INTEGER NOT NULL
+, old TEXT
+, new TEXT
+, FOREIGN KEY (changeset) REFERENCES changeset(id)
+, CHECK ( op IN
+		('INSERT'
+		,'UPDATE'
+		,'DELETE'
+		)
+	)
+);
+CREATE INDEX change_row ON change(table_name, row_id);
+CREATE TRIGGER schedule_insert AFTER INSERT ON schedule
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'schedule', 'INSERT', NEW.rowid, NULL, json_object('name', NEW.name) FROM changeset WHERE open;
+END;
+CREATE TRIGGER schedule_update AFTER UPDATE ON schedule
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'schedule', 'UPDATE', NEW.rowid, json_object('name', OLD.name), json_object('name', NEW.name) FROM changeset WHERE open;
+END;
+CREATE TRIGGER schedule_delete AFTER DELETE ON schedule
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'schedule', 'DELETE', OLD.rowid, json_object('name', OLD.name), NULL FROM changeset WHERE open;
+END;
+CREATE TRIGGER person_insert AFTER INSERT ON person
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'person', 'INSERT', NEW.rowid, NULL, json_object('name', NEW.name, 'display_name', NEW.display_name, 'email', NEW.email, 'timezone', NEW.timezone) FROM changeset WHERE open;
+END;
+CREATE TRIGGER person_update AFTER UPDATE ON person
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'person', 'UPDATE', NEW.rowid, json_object('name', OLD.name, 'display_name', OLD.display_name, 'email', OLD.email, 'timezone', OLD.timezone), json_object('name', NEW.name, 'display_name', NEW.display_name, 'email', NEW.email, 'timezone', NEW.timezone) FROM changeset WHERE open;
+END;
+CREATE TRIGGER person_delete AFTER DELETE ON person
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'person', 'DELETE', OLD.rowid, json_object('name', OLD.name, 'display_name', OLD.display_name, 'email', OLD.email, 'timezone', OLD.timezone), NULL FROM changeset WHERE open;
+END;
+CREATE TRIGGER identity_insert AFTER INSERT ON identity
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'identity', 'INSERT', NEW.rowid, NULL, json_object('person', NEW.person, 'destination', NEW.destination, 'name', NEW.name) FROM changeset WHERE open;
+END;
+CREATE TRIGGER identity_update AFTER UPDATE ON identity
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'identity', 'UPDATE', NEW.rowid, json_object('person', OLD.person, 'destination', OLD.destination, 'name', OLD.name), json_object('person', NEW.person, 'destination', NEW.destination, 'name', NEW.name) FROM changeset WHERE open;
+END;
+CREATE TRIGGER identity_delete AFTER DELETE ON identity
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'identity', 'DELETE', OLD.rowid, json_object('person', OLD.person, 'destination', OLD.destination, 'name', OLD.name), NULL FROM changeset WHERE open;
+END;
+CREATE TRIGGER event_insert AFTER INSERT ON event
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'event', 'INSERT', NEW.rowid, NULL, json_object('person', NEW.person, 'schedule', NEW.schedule, 'kind', NEW.kind, 'at', NEW.at) FROM changeset WHERE open;
+END;
+CREATE TRIGGER event_update AFTER UPDATE ON event
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'event', 'UPDATE', NEW.rowid, json_object('person', OLD.person, 'schedule', OLD.schedule, 'kind', OLD.kind, 'at', OLD.at), json_object('person', NEW.person, 'schedule', NEW.schedule, 'kind', NEW.kind, 'at', NEW.at) FROM changeset WHERE open;
+END;
+CREATE TRIGGER event_delete AFTER DELETE ON event
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'event', 'DELETE', OLD.rowid, json_object('person', OLD.person, 'schedule', OLD.schedule, 'kind', OLD.kind, 'at', OLD.at), NULL FROM changeset WHERE open;
+END;
+CREATE TRIGGER interval_insert AFTER INSERT ON interval
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'interval', 'INSERT', NEW.rowid, NULL, json_object('person', NEW.person, 'schedule', NEW.schedule, 'start_at', NEW.start_at, 'end_before', NEW.end_before, 'kind', NEW.kind, 'generation', NEW.generation, 'layer', NEW.layer, 'weight', NEW.weight, 'source', NEW.source, 'author', NEW.author, 'reason', NEW.reason, 'created_at', NEW.created_at, 'priority', NEW.priority, 'removed_at', NEW.removed_at, 'removed_by', NEW.removed_by) FROM changeset WHERE open;
+END;
+CREATE TRIGGER interval_update AFTER UPDATE ON interval
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'interval', 'UPDATE', NEW.rowid, json_object('person', OLD.person, 'schedule', OLD.schedule, 'start_at', OLD.start_at, 'end_before', OLD.end_before, 'kind', OLD.kind, 'generation', OLD.generation, 'layer', OLD.layer, 'weight', OLD.weight, 'source', OLD.source, 'author', OLD.author, 'reason', OLD.reason, 'created_at', OLD.created_at, 'priority', OLD.priority, 'removed_at', OLD.removed_at, 'removed_by', OLD.removed_by), json_object('person', NEW.person, 'schedule', NEW.schedule, 'start_at', NEW.start_at, 'end_before', NEW.end_before, 'kind', NEW.kind, 'generation', NEW.generation, 'layer', NEW.layer, 'weight', NEW.weight, 'source', NEW.source, 'author', NEW.author, 'reason', NEW.reason, 'created_at', NEW.created_at, 'priority', NEW.priority, 'removed_at', NEW.removed_at, 'removed_by', NEW.removed_by) FROM changeset WHERE open;
+END;
+CREATE TRIGGER interval_delete AFTER DELETE ON interval
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'interval', 'DELETE', OLD.rowid, json_object('person', OLD.person, 'schedule', OLD.schedule, 'start_at', OLD.start_at, 'end_before', OLD.end_before, 'kind', OLD.kind, 'generation', OLD.generation, 'layer', OLD.layer, 'weight', OLD.weight, 'source', OLD.source, 'author', OLD.author, 'reason', OLD.reason, 'created_at', OLD.created_at, 'priority', OLD.priority, 'removed_at', OLD.removed_at, 'removed_by', OLD.removed_by), NULL FROM changeset WHERE open;
+END;
+CREATE TRIGGER tag_insert AFTER INSERT ON tag
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'tag', 'INSERT', NEW.rowid, NULL, json_object('person', NEW.person, 'schedule', NEW.schedule, 'tag', NEW.tag) FROM changeset WHERE open;
+END;
+CREATE TRIGGER tag_update AFTER UPDATE ON tag
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'tag', 'UPDATE', NEW.rowid, json_object('person', OLD.person, 'schedule', OLD.schedule, 'tag', OLD.tag), json_object('person', NEW.person, 'schedule', NEW.schedule, 'tag', NEW.tag) FROM changeset WHERE open;
+END;
+CREATE TRIGGER tag_delete AFTER DELETE ON tag
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'tag', 'DELETE', OLD.rowid, json_object('person', OLD.person, 'schedule', OLD.schedule, 'tag', OLD.tag), NULL FROM changeset WHERE open;
+END;
+CREATE TRIGGER holiday_insert AFTER INSERT ON holiday
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'holiday', 'INSERT', NEW.rowid, NULL, json_object('schedule', NEW.schedule, 'name', NEW.name, 'start_at', NEW.start_at, 'end_before', NEW.end_before) FROM changeset WHERE open;
+END;
+CREATE TRIGGER holiday_update AFTER UPDATE ON holiday
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'holiday', 'UPDATE', NEW.rowid, json_object('schedule', OLD.schedule, 'name', OLD.name, 'start_at', OLD.start_at, 'end_before', OLD.end_before), json_object('schedule', NEW.schedule, 'name', NEW.name, 'start_at', NEW.start_at, 'end_before', NEW.end_before) FROM changeset WHERE open;
+END;
+CREATE TRIGGER holiday_delete AFTER DELETE ON holiday
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'holiday', 'DELETE', OLD.rowid, json_object('schedule', OLD.schedule, 'name', OLD.name, 'start_at', OLD.start_at, 'end_before', OLD.end_before), NULL FROM changeset WHERE open;
+END;
+CREATE TRIGGER constraints_insert AFTER INSERT ON constraints
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'constraints', 'INSERT', NEW.rowid, NULL, json_object('schedule', NEW.schedule, 'min_rest_seconds', NEW.min_rest_seconds, 'max_consecutive', NEW.max_consecutive, 'max_seconds_per_30_days', NEW.max_seconds_per_30_days) FROM changeset WHERE open;
+END;
+CREATE TRIGGER constraints_update AFTER UPDATE ON constraints
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'constraints', 'UPDATE', NEW.rowid, json_object('schedule', OLD.schedule, 'min_rest_seconds', OLD.min_rest_seconds, 'max_consecutive', OLD.max_consecutive, 'max_seconds_per_30_days', OLD.max_seconds_per_30_days), json_object('schedule', NEW.schedule, 'min_rest_seconds', NEW.min_rest_seconds, 'max_consecutive', NEW.max_consecutive, 'max_seconds_per_30_days', NEW.max_seconds_per_30_days) FROM changeset WHERE open;
+END;
+CREATE TRIGGER constraints_delete AFTER DELETE ON constraints
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'constraints', 'DELETE', OLD.rowid, json_object('schedule', OLD.schedule, 'min_rest_seconds', OLD.min_rest_seconds, 'max_consecutive', OLD.max_consecutive, 'max_seconds_per_30_days', OLD.max_seconds_per_30_days), NULL FROM changeset WHERE open;
+END;
+CREATE TRIGGER generation_insert AFTER INSERT ON generation
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'generation', 'INSERT', NEW.rowid, NULL, json_object('schedule', NEW.schedule, 'style', NEW.style, 'params', NEW.params, 'start_at', NEW.start_at, 'end_before', NEW.end_before, 'seed', NEW.seed) FROM changeset WHERE open;
+END;
+CREATE TRIGGER generation_update AFTER UPDATE ON generation
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'generation', 'UPDATE', NEW.rowid, json_object('schedule', OLD.schedule, 'style', OLD.style, 'params', OLD.params, 'start_at', OLD.start_at, 'end_before', OLD.end_before, 'seed', OLD.seed), json_object('schedule', NEW.schedule, 'style', NEW.style, 'params', NEW.params, 'start_at', NEW.start_at, 'end_before', NEW.end_before, 'seed', NEW.seed) FROM changeset WHERE open;
+END;
+CREATE TRIGGER generation_delete AFTER DELETE ON generation
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'generation', 'DELETE', OLD.rowid, json_object('schedule', OLD.schedule, 'style', OLD.style, 'params', OLD.params, 'start_at', OLD.start_at, 'end_before', OLD.end_before, 'seed', OLD.seed), NULL FROM changeset WHERE open;
+END;
+CREATE TRIGGER layer_insert AFTER INSERT ON layer
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'layer', 'INSERT', NEW.rowid, NULL, json_object('schedule', NEW.schedule, 'name', NEW.name) FROM changeset WHERE open;
+END;
+CREATE TRIGGER layer_update AFTER UPDATE ON layer
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'layer', 'UPDATE', NEW.rowid, json_object('schedule', OLD.schedule, 'name', OLD.name), json_object('schedule', NEW.schedule, 'name', NEW.name) FROM changeset WHERE open;
+END;
+CREATE TRIGGER layer_delete AFTER DELETE ON layer
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'layer', 'DELETE', OLD.rowid, json_object('schedule', OLD.schedule, 'name', OLD.name), NULL FROM changeset WHERE open;
+END;
+CREATE TRIGGER requirement_insert AFTER INSERT ON requirement
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'requirement', 'INSERT', NEW.rowid, NULL, json_object('schedule', NEW.schedule, 'tag', NEW.tag, 'at_least', NEW.at_least, 'at_most', NEW.at_most) FROM changeset WHERE open;
+END;
+CREATE TRIGGER requirement_update AFTER UPDATE ON requirement
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'requirement', 'UPDATE', NEW.rowid, json_object('schedule', OLD.schedule, 'tag', OLD.tag, 'at_least', OLD.at_least, 'at_most', OLD.at_most), json_object('schedule', NEW.schedule, 'tag', NEW.tag, 'at_least', NEW.at_least, 'at_most', NEW.at_most) FROM changeset WHERE open;
+END;
+CREATE TRIGGER requirement_delete AFTER DELETE ON requirement
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'requirement', 'DELETE', OLD.rowid, json_object('schedule', OLD.schedule, 'tag', OLD.tag, 'at_least', OLD.at_least, 'at_most', OLD.at_most), NULL FROM changeset WHERE open;
+END;
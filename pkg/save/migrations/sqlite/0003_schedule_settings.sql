@@ -0,0 +1,54 @@
+-- Settings are what commands that take a schedule fall back on when they aren't told otherwise.
+CREATE TABLE settings
+( schedule TEXT PRIMARY KEY
+, timezone TEXT NOT NULL DEFAULT ''
+, description TEXT NOT NULL DEFAULT ''
+, style TEXT NOT NULL DEFAULT ''
+, params TEXT NOT NULL DEFAULT ''
+, FOREIGN KEY (schedule) REFERENCES schedule(name)
+);
+CREATE TABLE remote_schedule
+( schedule TEXT NOT NULL
+, destination TEXT NOT NULL
+, name TEXT NOT NULL
+, FOREIGN KEY (schedule) REFERENCES schedule(name)
+, UNIQUE (schedule, destination)
+, CHECK ( destination != '' )
+, CHECK ( name != '' )
+);
+CREATE TRIGGER settings_insert AFTER INSERT ON settings
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'settings', 'INSERT', NEW.rowid, NULL, json_object('schedule', NEW.schedule, 'timezone', NEW.timezone, 'description', NEW.description, 'style', NEW.style, 'params', NEW.params) FROM changeset WHERE open;
+END;
+CREATE TRIGGER settings_update AFTER UPDATE ON settings
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'settings', 'UPDATE', NEW.rowid, json_object('schedule', OLD.schedule, 'timezone', OLD.timezone, 'description', OLD.description, 'style', OLD.style, 'params', OLD.params), json_object('schedule', NEW.schedule, 'timezone', NEW.timezone, 'description', NEW.description, 'style', NEW.style, 'params', NEW.params) FROM changeset WHERE open;
+END;
+CREATE TRIGGER settings_delete AFTER DELETE ON settings
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'settings', 'DELETE', OLD.rowid, json_object('schedule', OLD.schedule, 'timezone', OLD.timezone, 'description', OLD.description, 'style', OLD.style, 'params', OLD.params), NULL FROM changeset WHERE open;
+END;
+CREATE TRIGGER remote_schedule_insert AFTER INSERT ON remote_schedule
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'remote_schedule', 'INSERT', NEW.rowid, NULL, json_object('schedule', NEW.schedule, 'destination', NEW.destination, 'name', NEW.name) FROM changeset WHERE open;
+END;
+CREATE TRIGGER remote_schedule_update AFTER UPDATE ON remote_schedule
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'remote_schedule', 'UPDATE', NEW.rowid, json_object('schedule', OLD.schedule, 'destination', OLD.destination, 'name', OLD.name), json_object('schedule', NEW.schedule, 'destination', NEW.destination, 'name', NEW.name) FROM changeset WHERE open;
+END;
+CREATE TRIGGER remote_schedule_delete AFTER DELETE ON remote_schedule
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'remote_schedule', 'DELETE', OLD.rowid, json_object('schedule', OLD.schedule, 'destination', OLD.destination, 'name', OLD.name), NULL FROM changeset WHERE open;
+END;
@@ -0,0 +1,23 @@
+-- Archived schedules are hidden from list-schedules, and only they can be deleted.
+ALTER TABLE schedule ADD COLUMN archived_at TIMESTAMP;
+DROP TRIGGER schedule_insert;
+DROP TRIGGER schedule_update;
+DROP TRIGGER schedule_delete;
+CREATE TRIGGER schedule_insert AFTER INSERT ON schedule
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'schedule', 'INSERT', NEW.rowid, NULL, json_object('name', NEW.name, 'archived_at', NEW.archived_at) FROM changeset WHERE open;
+END;
+CREATE TRIGGER schedule_update AFTER UPDATE ON schedule
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'schedule', 'UPDATE', NEW.rowid, json_object('name', OLD.name, 'archived_at', OLD.archived_at), json_object('name', NEW.name, 'archived_at', NEW.archived_at) FROM changeset WHERE open;
+END;
+CREATE TRIGGER schedule_delete AFTER DELETE ON schedule
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'schedule', 'DELETE', OLD.rowid, json_object('name', OLD.name, 'archived_at', OLD.archived_at), NULL FROM changeset WHERE open;
+END;
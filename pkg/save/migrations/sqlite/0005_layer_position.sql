@@ -0,0 +1,29 @@
+-- Layers keep their order in a column of their own instead of relying on how the database stores them.
+ALTER TABLE layer ADD COLUMN position INTEGER NOT NULL DEFAULT 0;
+UPDATE layer SET position =
+( SELECT count(*) FROM layer AS earlier
+  WHERE earlier.schedule = layer.schedule
+  AND earlier.rowid <= layer.rowid
+);
+CREATE UNIQUE INDEX layer_position ON layer(schedule, position);
+DROP TRIGGER layer_insert;
+DROP TRIGGER layer_update;
+DROP TRIGGER layer_delete;
+CREATE TRIGGER layer_insert AFTER INSERT ON layer
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'layer', 'INSERT', NEW.rowid, NULL, json_object('schedule', NEW.schedule, 'name', NEW.name, 'position', NEW.position) FROM changeset WHERE open;
+END;
+CREATE TRIGGER layer_update AFTER UPDATE ON layer
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'layer', 'UPDATE', NEW.rowid, json_object('schedule', OLD.schedule, 'name', OLD.name, 'position', OLD.position), json_object('schedule', NEW.schedule, 'name', NEW.name, 'position', NEW.position) FROM changeset WHERE open;
+END;
+CREATE TRIGGER layer_delete AFTER DELETE ON layer
+WHEN EXISTS (SELECT 1 FROM changeset WHERE open)
+BEGIN
+INSERT INTO change(changeset, table_name, op, row_id, old, new)
+SELECT id, 'layer', 'DELETE', OLD.rowid, json_object('schedule', OLD.schedule, 'name', OLD.name, 'position', OLD.position), NULL FROM changeset WHERE open;
+END;
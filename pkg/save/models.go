@@ -5,9 +5,37 @@
 package save
 
 import (
+	"database/sql"
 	"time"
 )
 
+type Change struct {
+	ID        int64
+	Changeset int64
+	TableName string
+	Op        string
+	RowID     int64
+	Old       sql.NullString
+	New       sql.NullString
+}
+
+type Changeset struct {
+	ID      int64
+	Command string
+	Args    string
+	Author  string
+	At      time.Time
+	Open    bool
+	Undoes  sql.NullInt64
+}
+
+type Constraint struct {
+	Schedule            string
+	MinRestSeconds      int64
+	MaxConsecutive      int64
+	MaxSecondsPer30Days int64
+}
+
 type Event struct {
 	Person   string
 	Schedule string
@@ -15,14 +43,93 @@ type Event struct {
 	At       time.Time
 }
 
-type Interval struct {
-	Person    string
+type Generation struct {
+	ID        int64
+	Schedule  string
+	Style     string
+	Params    string
+	StartAt   time.Time
+	EndBefore time.Time
+	Seed      int64
+}
+
+type Holiday struct {
 	Schedule  string
+	Name      string
 	StartAt   time.Time
 	EndBefore time.Time
-	Kind      string
+}
+
+type Identity struct {
+	Person      string
+	Destination string
+	Name        string
+}
+
+type Interval struct {
+	ID         int64
+	Person     string
+	Schedule   string
+	StartAt    time.Time
+	EndBefore  time.Time
+	Kind       string
+	Generation sql.NullInt64
+	Layer      string
+	Weight     float64
+	Source     string
+	Author     string
+	Reason     string
+	CreatedAt  time.Time
+	Priority   int64
+	RemovedAt  sql.NullTime
+	RemovedBy  string
+}
+
+type Layer struct {
+	Schedule string
+	Name     string
+	Position int64
+}
+
+type Person struct {
+	Name        string
+	DisplayName string
+	Email       string
+	Timezone    string
+}
+
+type RemoteSchedule struct {
+	Schedule    string
+	Destination string
+	Name        string
+}
+
+type Requirement struct {
+	Schedule string
+	Tag      string
+	AtLeast  int64
+	AtMost   int64
 }
 
 type Schedule struct {
-	Name string
+	Name       string
+	ArchivedAt sql.NullTime
+}
+
+type SchemaVersion struct {
+	Version int64
+}
+
+type Setting struct {
+	Schedule    string
+	Timezone    string
+	Description string
+	Style       string
+	Params      string
+}
+
+type Tag struct {
+	Person   string
+	Schedule string
+	Tag      string
 }
@@ -0,0 +1,190 @@
+package save
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// A dialect is the flavour of SQL that a database speaks.
+// Queries stick to what both dialects share, and [Migrate] keeps the migrations for each apart.
+type dialect string
+
+const (
+	sqlite   dialect = "sqlite"
+	postgres dialect = "postgres"
+)
+
+func dialectOf(db *sql.DB) dialect {
+	if _, ok := db.Driver().(postgresDriver); ok {
+		return postgres
+	}
+	return sqlite
+}
+
+func isPostgres(dsn string) bool {
+	return strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://")
+}
+
+// openPostgres connects to PostgreSQL with the options added to the DSN's query.
+// Sessions are in UTC unless the options say otherwise.
+func openPostgres(dsn string, opts url.Values) (*sql.DB, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, err
+	}
+	query := u.Query()
+	if query.Get("timezone") == "" {
+		query.Set("timezone", "UTC")
+	}
+	for k, vs := range opts {
+		query[k] = vs
+	}
+	u.RawQuery = query.Encode()
+	c, err := pq.NewConnector(u.String())
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(postgresConnector{c}), nil
+}
+
+// postgresConnector hands out connections that take the same ? placeholders as SQLite,
+// so the same queries run on both.
+type postgresConnector struct{ driver.Connector }
+
+func (c postgresConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return postgresConn{conn}, nil
+}
+
+func (postgresConnector) Driver() driver.Driver { return postgresDriver{} }
+
+type postgresDriver struct{}
+
+func (postgresDriver) Open(dsn string) (driver.Conn, error) {
+	c, err := pq.NewConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return postgresConnector{c}.Connect(context.Background())
+}
+
+type postgresConn struct{ driver.Conn }
+
+func (c postgresConn) Prepare(query string) (driver.Stmt, error) {
+	return c.Conn.Prepare(rebind(query))
+}
+
+func (c postgresConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, rebind(query))
+	}
+	return c.Prepare(query)
+}
+
+func (c postgresConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	rows, err := q.QueryContext(ctx, rebind(query), args)
+	if err != nil {
+		return nil, err
+	}
+	return postgresRows{rows}, nil
+}
+
+func (c postgresConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	return e.ExecContext(ctx, rebind(query), args)
+}
+
+func (c postgresConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c postgresConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// postgresRows reads timestamps in UTC, the same as SQLite does, rather than at a fixed offset.
+type postgresRows struct{ driver.Rows }
+
+func (r postgresRows) Next(dest []driver.Value) error {
+	if err := r.Rows.Next(dest); err != nil {
+		return err
+	}
+	for i, v := range dest {
+		if t, ok := v.(time.Time); ok {
+			dest[i] = t.UTC()
+		}
+	}
+	return nil
+}
+
+// rebind turns SQLite's ? and ?NNN placeholders into PostgreSQL's $N.
+// It leaves alone anything in quotes, comments and dollar-quoted function bodies.
+func rebind(query string) string {
+	var (
+		b strings.Builder
+		n int
+	)
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		var end string
+		switch {
+		case c == '\'' || c == '"':
+			end = string(c)
+		case strings.HasPrefix(query[i:], "--"):
+			end = "\n"
+		case strings.HasPrefix(query[i:], "$$"):
+			end = "$$"
+			b.WriteString("$")
+			i++
+		case c == '?':
+			j := i + 1
+			for j < len(query) && '0' <= query[j] && query[j] <= '9' {
+				j++
+			}
+			if j > i+1 {
+				b.WriteString("$" + query[i+1:j])
+			} else {
+				n++
+				b.WriteString("$" + strconv.Itoa(n))
+			}
+			i = j - 1
+			continue
+		default:
+			b.WriteByte(c)
+			continue
+		}
+		// Copy through to the end of the quote, comment or body.
+		b.WriteByte(c)
+		k := strings.Index(query[i+1:], end)
+		if k < 0 {
+			b.WriteString(query[i+1:])
+			break
+		}
+		b.WriteString(query[i+1 : i+1+k+len(end)])
+		i += k + len(end)
+	}
+	return b.String()
+}
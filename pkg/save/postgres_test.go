@@ -0,0 +1,35 @@
+package save
+
+import (
+	"testing"
+
+	"github.com/jreut/pager/v2/pkg/assert"
+)
+
+func TestRebind(t *testing.T) {
+	for _, tt := range []struct {
+		in, want string
+	}{
+		{`SELECT 1`, `SELECT 1`},
+		{`SELECT * FROM t WHERE a = ? AND b = ?`, `SELECT * FROM t WHERE a = $1 AND b = $2`},
+		{`INSERT INTO t VALUES (?1, ?2, ?1)`, `INSERT INTO t VALUES ($1, $2, $1)`},
+		{`SELECT '?' || "a?" FROM t WHERE a = ?`, `SELECT '?' || "a?" FROM t WHERE a = $1`},
+		{"-- why?\nSELECT ?", "-- why?\nSELECT $1"},
+		{`CREATE FUNCTION f() AS $$ SELECT ? $$; SELECT ?`, `CREATE FUNCTION f() AS $$ SELECT ? $$; SELECT $1`},
+		{`SELECT 'unterminated ?`, `SELECT 'unterminated ?`},
+	} {
+		assert.Cmp(t, tt.want, rebind(tt.in))
+	}
+}
+
+func TestIsPostgres(t *testing.T) {
+	for in, want := range map[string]bool{
+		"db.sqlite3":                     false,
+		":memory:":                       false,
+		"file:postgres.sqlite3":          false,
+		"postgres://localhost/pager":     true,
+		"postgresql://u@localhost/pager": true,
+	} {
+		assert.Cmp(t, want, isPostgres(in))
+	}
+}
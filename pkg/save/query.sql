@@ -2,12 +2,185 @@
 INSERT INTO schedule(name) VALUES (?);
 
 -- name: AddInterval :exec
-INSERT INTO interval(person, schedule, start_at, end_before, kind)
-VALUES (?, ?, ?, ?, ?);
+INSERT INTO interval(person, schedule, start_at, end_before, kind, generation, layer, weight, source, author, reason, created_at, priority)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
 
 -- name: AddEvent :exec
 INSERT INTO event(person, schedule, kind, at)
 VALUES (?, ?, ?, ?);
 
 -- name: ListEvents :many
-SELECT * FROM event WHERE schedule = ? ORDER BY at ASC
+SELECT * FROM event WHERE schedule = ? ORDER BY at ASC, rowid ASC;
+
+-- name: ListShadows :many
+SELECT * FROM event WHERE schedule = ? AND kind = 'SHADOW' ORDER BY rowid ASC;
+
+-- name: AddTag :exec
+INSERT INTO tag(person, schedule, tag)
+VALUES (?, ?, ?)
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveTag :exec
+DELETE FROM tag WHERE person = ? AND schedule = ? AND tag = ?;
+
+-- name: ListTags :many
+SELECT * FROM tag WHERE schedule = ? ORDER BY person, tag;
+
+-- name: AddHoliday :exec
+INSERT INTO holiday(schedule, name, start_at, end_before)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (schedule, start_at) DO UPDATE
+SET name = excluded.name, end_before = excluded.end_before;
+
+-- name: ListHolidays :many
+SELECT * FROM holiday WHERE schedule = ? ORDER BY start_at;
+
+-- name: SetConstraints :exec
+INSERT INTO constraints(schedule, min_rest_seconds, max_consecutive, max_seconds_per_30_days)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (schedule) DO UPDATE
+SET min_rest_seconds = excluded.min_rest_seconds
+, max_consecutive = excluded.max_consecutive
+, max_seconds_per_30_days = excluded.max_seconds_per_30_days;
+
+-- name: GetConstraints :one
+SELECT * FROM constraints WHERE schedule = ?;
+
+-- name: AddGeneration :one
+INSERT INTO generation(schedule, style, params, start_at, end_before, seed)
+VALUES (?, ?, ?, ?, ?, ?)
+RETURNING id;
+
+-- name: ListGenerations :many
+SELECT * FROM generation WHERE schedule = ? ORDER BY id;
+
+-- name: AddLayer :exec
+INSERT INTO layer(schedule, name, position)
+VALUES (?1, ?2, (SELECT coalesce(max(position), 0) + 1 FROM layer WHERE schedule = ?1));
+
+-- name: ListLayers :many
+SELECT name FROM layer WHERE schedule = ? ORDER BY position;
+
+-- name: SetRequirement :exec
+INSERT INTO requirement(schedule, tag, at_least, at_most)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (schedule, tag) DO UPDATE
+SET at_least = excluded.at_least
+, at_most = excluded.at_most;
+
+-- name: RemoveRequirement :exec
+DELETE FROM requirement WHERE schedule = ? AND tag = ?;
+
+-- name: ListRequirements :many
+SELECT * FROM requirement WHERE schedule = ? ORDER BY tag;
+
+-- name: AddPerson :exec
+INSERT INTO person(name, display_name, email, timezone)
+VALUES (?, ?, ?, ?);
+
+-- name: UpdatePerson :exec
+UPDATE person SET display_name = ?, email = ?, timezone = ? WHERE name = ?;
+
+-- name: GetPerson :one
+SELECT * FROM person WHERE name = ?;
+
+-- name: ListPeople :many
+SELECT * FROM person ORDER BY name;
+
+-- name: SetIdentity :exec
+INSERT INTO identity(person, destination, name)
+VALUES (?, ?, ?)
+ON CONFLICT (person, destination) DO UPDATE
+SET name = excluded.name;
+
+-- name: RemoveIdentity :exec
+DELETE FROM identity WHERE person = ? AND destination = ?;
+
+-- name: ListIdentities :many
+SELECT * FROM identity ORDER BY person, destination;
+
+-- name: GetInterval :one
+SELECT * FROM interval WHERE id = ?;
+
+-- name: FindIntervals :many
+SELECT * FROM interval
+WHERE schedule = ?
+AND person = ?
+AND kind = ?
+AND layer = ?
+AND start_at = ?
+AND end_before = ?
+AND removed_at IS NULL
+ORDER BY id;
+
+-- name: RemoveInterval :exec
+UPDATE interval SET removed_at = ?, removed_by = ? WHERE id = ?;
+
+-- name: OpenChangeset :one
+INSERT INTO changeset(command, args, author, at, open)
+VALUES (?, ?, ?, ?, TRUE)
+RETURNING id;
+
+-- name: CloseChangeset :exec
+UPDATE changeset SET open = FALSE WHERE id = ?;
+
+-- name: DeleteChangeset :exec
+DELETE FROM changeset WHERE id = ?;
+
+-- name: SetUndoes :exec
+UPDATE changeset SET undoes = ? WHERE open;
+
+-- name: GetChangeset :one
+SELECT * FROM changeset WHERE id = ?;
+
+-- name: ListChangesets :many
+SELECT * FROM changeset ORDER BY id;
+
+-- name: ListChanges :many
+SELECT * FROM change WHERE changeset = ? ORDER BY id;
+
+-- name: CountChanges :one
+SELECT count(*) FROM change WHERE changeset = ?;
+
+-- name: GetSchedule :one
+SELECT * FROM schedule WHERE name = ?;
+
+-- name: ListSchedules :many
+SELECT * FROM schedule ORDER BY name;
+
+-- name: ArchiveSchedule :exec
+UPDATE schedule SET archived_at = ? WHERE name = ?;
+
+-- name: CountIntervals :one
+SELECT count(*) FROM interval WHERE schedule = ? AND removed_at IS NULL;
+
+-- name: GetSettings :one
+SELECT * FROM settings WHERE schedule = ?;
+
+-- name: SetSettings :exec
+INSERT INTO settings(schedule, timezone, description, style, params)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (schedule) DO UPDATE
+SET timezone = excluded.timezone
+, description = excluded.description
+, style = excluded.style
+, params = excluded.params;
+
+-- name: SetRemoteSchedule :exec
+INSERT INTO remote_schedule(schedule, destination, name)
+VALUES (?, ?, ?)
+ON CONFLICT (schedule, destination) DO UPDATE
+SET name = excluded.name;
+
+-- name: RemoveRemoteSchedule :exec
+DELETE FROM remote_schedule WHERE schedule = ? AND destination = ?;
+
+-- name: ListRemoteSchedules :many
+SELECT * FROM remote_schedule WHERE schedule = ? ORDER BY destination;
+
+-- name: ListIntervalsEndingAfter :many
+SELECT * FROM interval
+WHERE schedule = ?
+AND end_before > ?
+AND removed_at IS NULL
+ORDER BY id;
@@ -7,6 +7,7 @@ package save
 
 import (
 	"context"
+	"database/sql"
 	"time"
 )
 
@@ -32,17 +33,78 @@ func (q *Queries) AddEvent(ctx context.Context, arg AddEventParams) error {
 	return err
 }
 
-const addInterval = `-- name: AddInterval :exec
-INSERT INTO interval(person, schedule, start_at, end_before, kind)
-VALUES (?, ?, ?, ?, ?)
+const addGeneration = `-- name: AddGeneration :one
+INSERT INTO generation(schedule, style, params, start_at, end_before, seed)
+VALUES (?, ?, ?, ?, ?, ?)
+RETURNING id
 `
 
-type AddIntervalParams struct {
-	Person    string
+type AddGenerationParams struct {
 	Schedule  string
+	Style     string
+	Params    string
 	StartAt   time.Time
 	EndBefore time.Time
-	Kind      string
+	Seed      int64
+}
+
+func (q *Queries) AddGeneration(ctx context.Context, arg AddGenerationParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, addGeneration,
+		arg.Schedule,
+		arg.Style,
+		arg.Params,
+		arg.StartAt,
+		arg.EndBefore,
+		arg.Seed,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const addHoliday = `-- name: AddHoliday :exec
+INSERT INTO holiday(schedule, name, start_at, end_before)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (schedule, start_at) DO UPDATE
+SET name = excluded.name, end_before = excluded.end_before
+`
+
+type AddHolidayParams struct {
+	Schedule  string
+	Name      string
+	StartAt   time.Time
+	EndBefore time.Time
+}
+
+func (q *Queries) AddHoliday(ctx context.Context, arg AddHolidayParams) error {
+	_, err := q.db.ExecContext(ctx, addHoliday,
+		arg.Schedule,
+		arg.Name,
+		arg.StartAt,
+		arg.EndBefore,
+	)
+	return err
+}
+
+const addInterval = `-- name: AddInterval :exec
+INSERT INTO interval(person, schedule, start_at, end_before, kind, generation, layer, weight, source, author, reason, created_at, priority)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+type AddIntervalParams struct {
+	Person     string
+	Schedule   string
+	StartAt    time.Time
+	EndBefore  time.Time
+	Kind       string
+	Generation sql.NullInt64
+	Layer      string
+	Weight     float64
+	Source     string
+	Author     string
+	Reason     string
+	CreatedAt  time.Time
+	Priority   int64
 }
 
 func (q *Queries) AddInterval(ctx context.Context, arg AddIntervalParams) error {
@@ -52,6 +114,51 @@ func (q *Queries) AddInterval(ctx context.Context, arg AddIntervalParams) error
 		arg.StartAt,
 		arg.EndBefore,
 		arg.Kind,
+		arg.Generation,
+		arg.Layer,
+		arg.Weight,
+		arg.Source,
+		arg.Author,
+		arg.Reason,
+		arg.CreatedAt,
+		arg.Priority,
+	)
+	return err
+}
+
+const addLayer = `-- name: AddLayer :exec
+INSERT INTO layer(schedule, name, position)
+VALUES (?1, ?2, (SELECT coalesce(max(position), 0) + 1 FROM layer WHERE schedule = ?1))
+`
+
+type AddLayerParams struct {
+	Schedule string
+	Name     string
+}
+
+func (q *Queries) AddLayer(ctx context.Context, arg AddLayerParams) error {
+	_, err := q.db.ExecContext(ctx, addLayer, arg.Schedule, arg.Name)
+	return err
+}
+
+const addPerson = `-- name: AddPerson :exec
+INSERT INTO person(name, display_name, email, timezone)
+VALUES (?, ?, ?, ?)
+`
+
+type AddPersonParams struct {
+	Name        string
+	DisplayName string
+	Email       string
+	Timezone    string
+}
+
+func (q *Queries) AddPerson(ctx context.Context, arg AddPersonParams) error {
+	_, err := q.db.ExecContext(ctx, addPerson,
+		arg.Name,
+		arg.DisplayName,
+		arg.Email,
+		arg.Timezone,
 	)
 	return err
 }
@@ -65,8 +172,327 @@ func (q *Queries) AddSchedule(ctx context.Context, name string) error {
 	return err
 }
 
+const addTag = `-- name: AddTag :exec
+INSERT INTO tag(person, schedule, tag)
+VALUES (?, ?, ?)
+ON CONFLICT DO NOTHING
+`
+
+type AddTagParams struct {
+	Person   string
+	Schedule string
+	Tag      string
+}
+
+func (q *Queries) AddTag(ctx context.Context, arg AddTagParams) error {
+	_, err := q.db.ExecContext(ctx, addTag, arg.Person, arg.Schedule, arg.Tag)
+	return err
+}
+
+const archiveSchedule = `-- name: ArchiveSchedule :exec
+UPDATE schedule SET archived_at = ? WHERE name = ?
+`
+
+type ArchiveScheduleParams struct {
+	ArchivedAt sql.NullTime
+	Name       string
+}
+
+func (q *Queries) ArchiveSchedule(ctx context.Context, arg ArchiveScheduleParams) error {
+	_, err := q.db.ExecContext(ctx, archiveSchedule, arg.ArchivedAt, arg.Name)
+	return err
+}
+
+const closeChangeset = `-- name: CloseChangeset :exec
+UPDATE changeset SET open = FALSE WHERE id = ?
+`
+
+func (q *Queries) CloseChangeset(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, closeChangeset, id)
+	return err
+}
+
+const countChanges = `-- name: CountChanges :one
+SELECT count(*) FROM change WHERE changeset = ?
+`
+
+func (q *Queries) CountChanges(ctx context.Context, changeset int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countChanges, changeset)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countIntervals = `-- name: CountIntervals :one
+SELECT count(*) FROM interval WHERE schedule = ? AND removed_at IS NULL
+`
+
+func (q *Queries) CountIntervals(ctx context.Context, schedule string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countIntervals, schedule)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteChangeset = `-- name: DeleteChangeset :exec
+DELETE FROM changeset WHERE id = ?
+`
+
+func (q *Queries) DeleteChangeset(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteChangeset, id)
+	return err
+}
+
+const findIntervals = `-- name: FindIntervals :many
+SELECT id, person, schedule, start_at, end_before, kind, generation, layer, weight, source, author, reason, created_at, priority, removed_at, removed_by FROM interval
+WHERE schedule = ?
+AND person = ?
+AND kind = ?
+AND layer = ?
+AND start_at = ?
+AND end_before = ?
+AND removed_at IS NULL
+ORDER BY id
+`
+
+type FindIntervalsParams struct {
+	Schedule  string
+	Person    string
+	Kind      string
+	Layer     string
+	StartAt   time.Time
+	EndBefore time.Time
+}
+
+func (q *Queries) FindIntervals(ctx context.Context, arg FindIntervalsParams) ([]Interval, error) {
+	rows, err := q.db.QueryContext(ctx, findIntervals,
+		arg.Schedule,
+		arg.Person,
+		arg.Kind,
+		arg.Layer,
+		arg.StartAt,
+		arg.EndBefore,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Interval
+	for rows.Next() {
+		var i Interval
+		if err := rows.Scan(
+			&i.ID,
+			&i.Person,
+			&i.Schedule,
+			&i.StartAt,
+			&i.EndBefore,
+			&i.Kind,
+			&i.Generation,
+			&i.Layer,
+			&i.Weight,
+			&i.Source,
+			&i.Author,
+			&i.Reason,
+			&i.CreatedAt,
+			&i.Priority,
+			&i.RemovedAt,
+			&i.RemovedBy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChangeset = `-- name: GetChangeset :one
+SELECT id, command, args, author, at, open, undoes FROM changeset WHERE id = ?
+`
+
+func (q *Queries) GetChangeset(ctx context.Context, id int64) (Changeset, error) {
+	row := q.db.QueryRowContext(ctx, getChangeset, id)
+	var i Changeset
+	err := row.Scan(
+		&i.ID,
+		&i.Command,
+		&i.Args,
+		&i.Author,
+		&i.At,
+		&i.Open,
+		&i.Undoes,
+	)
+	return i, err
+}
+
+const getConstraints = `-- name: GetConstraints :one
+SELECT schedule, min_rest_seconds, max_consecutive, max_seconds_per_30_days FROM constraints WHERE schedule = ?
+`
+
+func (q *Queries) GetConstraints(ctx context.Context, schedule string) (Constraint, error) {
+	row := q.db.QueryRowContext(ctx, getConstraints, schedule)
+	var i Constraint
+	err := row.Scan(
+		&i.Schedule,
+		&i.MinRestSeconds,
+		&i.MaxConsecutive,
+		&i.MaxSecondsPer30Days,
+	)
+	return i, err
+}
+
+const getInterval = `-- name: GetInterval :one
+SELECT id, person, schedule, start_at, end_before, kind, generation, layer, weight, source, author, reason, created_at, priority, removed_at, removed_by FROM interval WHERE id = ?
+`
+
+func (q *Queries) GetInterval(ctx context.Context, id int64) (Interval, error) {
+	row := q.db.QueryRowContext(ctx, getInterval, id)
+	var i Interval
+	err := row.Scan(
+		&i.ID,
+		&i.Person,
+		&i.Schedule,
+		&i.StartAt,
+		&i.EndBefore,
+		&i.Kind,
+		&i.Generation,
+		&i.Layer,
+		&i.Weight,
+		&i.Source,
+		&i.Author,
+		&i.Reason,
+		&i.CreatedAt,
+		&i.Priority,
+		&i.RemovedAt,
+		&i.RemovedBy,
+	)
+	return i, err
+}
+
+const getPerson = `-- name: GetPerson :one
+SELECT name, display_name, email, timezone FROM person WHERE name = ?
+`
+
+func (q *Queries) GetPerson(ctx context.Context, name string) (Person, error) {
+	row := q.db.QueryRowContext(ctx, getPerson, name)
+	var i Person
+	err := row.Scan(
+		&i.Name,
+		&i.DisplayName,
+		&i.Email,
+		&i.Timezone,
+	)
+	return i, err
+}
+
+const getSchedule = `-- name: GetSchedule :one
+SELECT name, archived_at FROM schedule WHERE name = ?
+`
+
+func (q *Queries) GetSchedule(ctx context.Context, name string) (Schedule, error) {
+	row := q.db.QueryRowContext(ctx, getSchedule, name)
+	var i Schedule
+	err := row.Scan(
+		&i.Name,
+		&i.ArchivedAt,
+	)
+	return i, err
+}
+
+const getSettings = `-- name: GetSettings :one
+SELECT schedule, timezone, description, style, params FROM settings WHERE schedule = ?
+`
+
+func (q *Queries) GetSettings(ctx context.Context, schedule string) (Setting, error) {
+	row := q.db.QueryRowContext(ctx, getSettings, schedule)
+	var i Setting
+	err := row.Scan(
+		&i.Schedule,
+		&i.Timezone,
+		&i.Description,
+		&i.Style,
+		&i.Params,
+	)
+	return i, err
+}
+
+const listChanges = `-- name: ListChanges :many
+SELECT id, changeset, table_name, op, row_id, old, new FROM change WHERE changeset = ? ORDER BY id
+`
+
+func (q *Queries) ListChanges(ctx context.Context, changeset int64) ([]Change, error) {
+	rows, err := q.db.QueryContext(ctx, listChanges, changeset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Change
+	for rows.Next() {
+		var i Change
+		if err := rows.Scan(
+			&i.ID,
+			&i.Changeset,
+			&i.TableName,
+			&i.Op,
+			&i.RowID,
+			&i.Old,
+			&i.New,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChangesets = `-- name: ListChangesets :many
+SELECT id, command, args, author, at, open, undoes FROM changeset ORDER BY id
+`
+
+func (q *Queries) ListChangesets(ctx context.Context) ([]Changeset, error) {
+	rows, err := q.db.QueryContext(ctx, listChangesets)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Changeset
+	for rows.Next() {
+		var i Changeset
+		if err := rows.Scan(
+			&i.ID,
+			&i.Command,
+			&i.Args,
+			&i.Author,
+			&i.At,
+			&i.Open,
+			&i.Undoes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listEvents = `-- name: ListEvents :many
-SELECT person, schedule, kind, at FROM event WHERE schedule = ? ORDER BY at ASC
+SELECT person, schedule, kind, at FROM event WHERE schedule = ? ORDER BY at ASC, rowid ASC
 `
 
 func (q *Queries) ListEvents(ctx context.Context, schedule string) ([]Event, error) {
@@ -96,3 +522,608 @@ func (q *Queries) ListEvents(ctx context.Context, schedule string) ([]Event, err
 	}
 	return items, nil
 }
+
+const listShadows = `-- name: ListShadows :many
+SELECT person, schedule, kind, at FROM event WHERE schedule = ? AND kind = 'SHADOW' ORDER BY rowid ASC
+`
+
+func (q *Queries) ListShadows(ctx context.Context, schedule string) ([]Event, error) {
+	rows, err := q.db.QueryContext(ctx, listShadows, schedule)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Event
+	for rows.Next() {
+		var i Event
+		if err := rows.Scan(
+			&i.Person,
+			&i.Schedule,
+			&i.Kind,
+			&i.At,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listGenerations = `-- name: ListGenerations :many
+SELECT id, schedule, style, params, start_at, end_before, seed FROM generation WHERE schedule = ? ORDER BY id
+`
+
+func (q *Queries) ListGenerations(ctx context.Context, schedule string) ([]Generation, error) {
+	rows, err := q.db.QueryContext(ctx, listGenerations, schedule)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Generation
+	for rows.Next() {
+		var i Generation
+		if err := rows.Scan(
+			&i.ID,
+			&i.Schedule,
+			&i.Style,
+			&i.Params,
+			&i.StartAt,
+			&i.EndBefore,
+			&i.Seed,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHolidays = `-- name: ListHolidays :many
+SELECT schedule, name, start_at, end_before FROM holiday WHERE schedule = ? ORDER BY start_at
+`
+
+func (q *Queries) ListHolidays(ctx context.Context, schedule string) ([]Holiday, error) {
+	rows, err := q.db.QueryContext(ctx, listHolidays, schedule)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Holiday
+	for rows.Next() {
+		var i Holiday
+		if err := rows.Scan(
+			&i.Schedule,
+			&i.Name,
+			&i.StartAt,
+			&i.EndBefore,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listIdentities = `-- name: ListIdentities :many
+SELECT person, destination, name FROM identity ORDER BY person, destination
+`
+
+func (q *Queries) ListIdentities(ctx context.Context) ([]Identity, error) {
+	rows, err := q.db.QueryContext(ctx, listIdentities)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Identity
+	for rows.Next() {
+		var i Identity
+		if err := rows.Scan(
+			&i.Person,
+			&i.Destination,
+			&i.Name,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listIntervalsEndingAfter = `-- name: ListIntervalsEndingAfter :many
+SELECT id, person, schedule, start_at, end_before, kind, generation, layer, weight, source, author, reason, created_at, priority, removed_at, removed_by FROM interval
+WHERE schedule = ?
+AND end_before > ?
+AND removed_at IS NULL
+ORDER BY id
+`
+
+type ListIntervalsEndingAfterParams struct {
+	Schedule  string
+	EndBefore time.Time
+}
+
+func (q *Queries) ListIntervalsEndingAfter(ctx context.Context, arg ListIntervalsEndingAfterParams) ([]Interval, error) {
+	rows, err := q.db.QueryContext(ctx, listIntervalsEndingAfter, arg.Schedule, arg.EndBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Interval
+	for rows.Next() {
+		var i Interval
+		if err := rows.Scan(
+			&i.ID,
+			&i.Person,
+			&i.Schedule,
+			&i.StartAt,
+			&i.EndBefore,
+			&i.Kind,
+			&i.Generation,
+			&i.Layer,
+			&i.Weight,
+			&i.Source,
+			&i.Author,
+			&i.Reason,
+			&i.CreatedAt,
+			&i.Priority,
+			&i.RemovedAt,
+			&i.RemovedBy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLayers = `-- name: ListLayers :many
+SELECT name FROM layer WHERE schedule = ? ORDER BY position
+`
+
+func (q *Queries) ListLayers(ctx context.Context, schedule string) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listLayers, schedule)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		items = append(items, name)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPeople = `-- name: ListPeople :many
+SELECT name, display_name, email, timezone FROM person ORDER BY name
+`
+
+func (q *Queries) ListPeople(ctx context.Context) ([]Person, error) {
+	rows, err := q.db.QueryContext(ctx, listPeople)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Person
+	for rows.Next() {
+		var i Person
+		if err := rows.Scan(
+			&i.Name,
+			&i.DisplayName,
+			&i.Email,
+			&i.Timezone,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRemoteSchedules = `-- name: ListRemoteSchedules :many
+SELECT schedule, destination, name FROM remote_schedule WHERE schedule = ? ORDER BY destination
+`
+
+func (q *Queries) ListRemoteSchedules(ctx context.Context, schedule string) ([]RemoteSchedule, error) {
+	rows, err := q.db.QueryContext(ctx, listRemoteSchedules, schedule)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RemoteSchedule
+	for rows.Next() {
+		var i RemoteSchedule
+		if err := rows.Scan(
+			&i.Schedule,
+			&i.Destination,
+			&i.Name,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRequirements = `-- name: ListRequirements :many
+SELECT schedule, tag, at_least, at_most FROM requirement WHERE schedule = ? ORDER BY tag
+`
+
+func (q *Queries) ListRequirements(ctx context.Context, schedule string) ([]Requirement, error) {
+	rows, err := q.db.QueryContext(ctx, listRequirements, schedule)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Requirement
+	for rows.Next() {
+		var i Requirement
+		if err := rows.Scan(
+			&i.Schedule,
+			&i.Tag,
+			&i.AtLeast,
+			&i.AtMost,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSchedules = `-- name: ListSchedules :many
+SELECT name, archived_at FROM schedule ORDER BY name
+`
+
+func (q *Queries) ListSchedules(ctx context.Context) ([]Schedule, error) {
+	rows, err := q.db.QueryContext(ctx, listSchedules)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Schedule
+	for rows.Next() {
+		var i Schedule
+		if err := rows.Scan(
+			&i.Name,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTags = `-- name: ListTags :many
+SELECT person, schedule, tag FROM tag WHERE schedule = ? ORDER BY person, tag
+`
+
+func (q *Queries) ListTags(ctx context.Context, schedule string) ([]Tag, error) {
+	rows, err := q.db.QueryContext(ctx, listTags, schedule)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Tag
+	for rows.Next() {
+		var i Tag
+		if err := rows.Scan(&i.Person, &i.Schedule, &i.Tag); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const openChangeset = `-- name: OpenChangeset :one
+INSERT INTO changeset(command, args, author, at, open)
+VALUES (?, ?, ?, ?, TRUE)
+RETURNING id
+`
+
+type OpenChangesetParams struct {
+	Command string
+	Args    string
+	Author  string
+	At      time.Time
+}
+
+func (q *Queries) OpenChangeset(ctx context.Context, arg OpenChangesetParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, openChangeset,
+		arg.Command,
+		arg.Args,
+		arg.Author,
+		arg.At,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const removeIdentity = `-- name: RemoveIdentity :exec
+DELETE FROM identity WHERE person = ? AND destination = ?
+`
+
+type RemoveIdentityParams struct {
+	Person      string
+	Destination string
+}
+
+func (q *Queries) RemoveIdentity(ctx context.Context, arg RemoveIdentityParams) error {
+	_, err := q.db.ExecContext(ctx, removeIdentity, arg.Person, arg.Destination)
+	return err
+}
+
+const removeInterval = `-- name: RemoveInterval :exec
+UPDATE interval SET removed_at = ?, removed_by = ? WHERE id = ?
+`
+
+type RemoveIntervalParams struct {
+	RemovedAt sql.NullTime
+	RemovedBy string
+	ID        int64
+}
+
+func (q *Queries) RemoveInterval(ctx context.Context, arg RemoveIntervalParams) error {
+	_, err := q.db.ExecContext(ctx, removeInterval, arg.RemovedAt, arg.RemovedBy, arg.ID)
+	return err
+}
+
+const removeRemoteSchedule = `-- name: RemoveRemoteSchedule :exec
+DELETE FROM remote_schedule WHERE schedule = ? AND destination = ?
+`
+
+type RemoveRemoteScheduleParams struct {
+	Schedule    string
+	Destination string
+}
+
+func (q *Queries) RemoveRemoteSchedule(ctx context.Context, arg RemoveRemoteScheduleParams) error {
+	_, err := q.db.ExecContext(ctx, removeRemoteSchedule, arg.Schedule, arg.Destination)
+	return err
+}
+
+const removeRequirement = `-- name: RemoveRequirement :exec
+DELETE FROM requirement WHERE schedule = ? AND tag = ?
+`
+
+type RemoveRequirementParams struct {
+	Schedule string
+	Tag      string
+}
+
+func (q *Queries) RemoveRequirement(ctx context.Context, arg RemoveRequirementParams) error {
+	_, err := q.db.ExecContext(ctx, removeRequirement, arg.Schedule, arg.Tag)
+	return err
+}
+
+const removeTag = `-- name: RemoveTag :exec
+DELETE FROM tag WHERE person = ? AND schedule = ? AND tag = ?
+`
+
+type RemoveTagParams struct {
+	Person   string
+	Schedule string
+	Tag      string
+}
+
+func (q *Queries) RemoveTag(ctx context.Context, arg RemoveTagParams) error {
+	_, err := q.db.ExecContext(ctx, removeTag, arg.Person, arg.Schedule, arg.Tag)
+	return err
+}
+
+const setConstraints = `-- name: SetConstraints :exec
+INSERT INTO constraints(schedule, min_rest_seconds, max_consecutive, max_seconds_per_30_days)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (schedule) DO UPDATE
+SET min_rest_seconds = excluded.min_rest_seconds
+, max_consecutive = excluded.max_consecutive
+, max_seconds_per_30_days = excluded.max_seconds_per_30_days
+`
+
+type SetConstraintsParams struct {
+	Schedule            string
+	MinRestSeconds      int64
+	MaxConsecutive      int64
+	MaxSecondsPer30Days int64
+}
+
+func (q *Queries) SetConstraints(ctx context.Context, arg SetConstraintsParams) error {
+	_, err := q.db.ExecContext(ctx, setConstraints,
+		arg.Schedule,
+		arg.MinRestSeconds,
+		arg.MaxConsecutive,
+		arg.MaxSecondsPer30Days,
+	)
+	return err
+}
+
+const setIdentity = `-- name: SetIdentity :exec
+INSERT INTO identity(person, destination, name)
+VALUES (?, ?, ?)
+ON CONFLICT (person, destination) DO UPDATE
+SET name = excluded.name
+`
+
+type SetIdentityParams struct {
+	Person      string
+	Destination string
+	Name        string
+}
+
+func (q *Queries) SetIdentity(ctx context.Context, arg SetIdentityParams) error {
+	_, err := q.db.ExecContext(ctx, setIdentity, arg.Person, arg.Destination, arg.Name)
+	return err
+}
+
+const setRemoteSchedule = `-- name: SetRemoteSchedule :exec
+INSERT INTO remote_schedule(schedule, destination, name)
+VALUES (?, ?, ?)
+ON CONFLICT (schedule, destination) DO UPDATE
+SET name = excluded.name
+`
+
+type SetRemoteScheduleParams struct {
+	Schedule    string
+	Destination string
+	Name        string
+}
+
+func (q *Queries) SetRemoteSchedule(ctx context.Context, arg SetRemoteScheduleParams) error {
+	_, err := q.db.ExecContext(ctx, setRemoteSchedule, arg.Schedule, arg.Destination, arg.Name)
+	return err
+}
+
+const setRequirement = `-- name: SetRequirement :exec
+INSERT INTO requirement(schedule, tag, at_least, at_most)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (schedule, tag) DO UPDATE
+SET at_least = excluded.at_least
+, at_most = excluded.at_most
+`
+
+type SetRequirementParams struct {
+	Schedule string
+	Tag      string
+	AtLeast  int64
+	AtMost   int64
+}
+
+func (q *Queries) SetRequirement(ctx context.Context, arg SetRequirementParams) error {
+	_, err := q.db.ExecContext(ctx, setRequirement,
+		arg.Schedule,
+		arg.Tag,
+		arg.AtLeast,
+		arg.AtMost,
+	)
+	return err
+}
+
+const setSettings = `-- name: SetSettings :exec
+INSERT INTO settings(schedule, timezone, description, style, params)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (schedule) DO UPDATE
+SET timezone = excluded.timezone
+, description = excluded.description
+, style = excluded.style
+, params = excluded.params
+`
+
+type SetSettingsParams struct {
+	Schedule    string
+	Timezone    string
+	Description string
+	Style       string
+	Params      string
+}
+
+func (q *Queries) SetSettings(ctx context.Context, arg SetSettingsParams) error {
+	_, err := q.db.ExecContext(ctx, setSettings,
+		arg.Schedule,
+		arg.Timezone,
+		arg.Description,
+		arg.Style,
+		arg.Params,
+	)
+	return err
+}
+
+const setUndoes = `-- name: SetUndoes :exec
+UPDATE changeset SET undoes = ? WHERE open
+`
+
+func (q *Queries) SetUndoes(ctx context.Context, undoes sql.NullInt64) error {
+	_, err := q.db.ExecContext(ctx, setUndoes, undoes)
+	return err
+}
+
+const updatePerson = `-- name: UpdatePerson :exec
+UPDATE person SET display_name = ?, email = ?, timezone = ? WHERE name = ?
+`
+
+type UpdatePersonParams struct {
+	DisplayName string
+	Email       string
+	Timezone    string
+	Name        string
+}
+
+func (q *Queries) UpdatePerson(ctx context.Context, arg UpdatePersonParams) error {
+	_, err := q.db.ExecContext(ctx, updatePerson,
+		arg.DisplayName,
+		arg.Email,
+		arg.Timezone,
+		arg.Name,
+	)
+	return err
+}
@@ -0,0 +1,104 @@
+package save
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Revert puts back a row the way it was before the change, using what the triggers recorded.
+//
+// Numbers go back as the text they were recorded as, which both SQLite and PostgreSQL convert to the type of their column.
+func (q *Queries) Revert(ctx context.Context, c Change) error {
+	table := quote(c.TableName)
+	if c.Op == "INSERT" {
+		return q.revert(ctx, c, `DELETE FROM `+table+` WHERE rowid = ?`, c.RowID)
+	}
+	d := json.NewDecoder(strings.NewReader(c.Old.String))
+	d.UseNumber()
+	var old map[string]interface{}
+	if err := d.Decode(&old); err != nil {
+		return fmt.Errorf("parsing %s row %d: %w", c.TableName, c.RowID, err)
+	}
+	var columns []string
+	for k := range old {
+		columns = append(columns, k)
+	}
+	sort.Strings(columns)
+	var (
+		args         []interface{}
+		quoted, sets []string
+	)
+	for _, k := range columns {
+		v := old[k]
+		if n, ok := v.(json.Number); ok {
+			v = n.String()
+		}
+		args = append(args, v)
+		quoted = append(quoted, quote(k))
+		sets = append(sets, quote(k)+` = ?`)
+	}
+	switch c.Op {
+	case "UPDATE":
+		return q.revert(ctx, c, `UPDATE `+table+` SET `+strings.Join(sets, ", ")+` WHERE rowid = ?`, append(args, c.RowID)...)
+	case "DELETE":
+		return q.revert(ctx, c, `INSERT INTO `+table+`(rowid, `+strings.Join(quoted, ", ")+`) VALUES (?`+strings.Repeat(`, ?`, len(columns))+`)`, append([]interface{}{c.RowID}, args...)...)
+	default:
+		return fmt.Errorf("unhandled op %q", c.Op)
+	}
+}
+
+func (q *Queries) revert(ctx context.Context, c Change, sql string, args ...interface{}) error {
+	result, err := q.db.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("reverting %s of %s row %d: %w", c.Op, c.TableName, c.RowID, err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n != 1 {
+		return fmt.Errorf("reverting %s of %s row %d: row is gone", c.Op, c.TableName, c.RowID)
+	}
+	return nil
+}
+
+func quote(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// ChangedSince lists the later changesets that touched any row the changeset did.
+func (q *Queries) ChangedSince(ctx context.Context, changeset int64) ([]int64, error) {
+	const sql = `
+SELECT DISTINCT later.changeset
+FROM change AS mine
+JOIN change AS later
+ON later.table_name = mine.table_name
+AND later.row_id = mine.row_id
+AND later.changeset > mine.changeset
+WHERE mine.changeset = ?
+ORDER BY later.changeset
+`
+	rows, err := q.db.QueryContext(ctx, sql, changeset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var i int64
+		if err := rows.Scan(&i); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
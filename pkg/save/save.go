@@ -2,9 +2,11 @@
 package save
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"net/url"
+	"strconv"
 	"time"
 
 	_ "embed"
@@ -15,31 +17,149 @@ import (
 const (
 	IntervalKindShift     = "SHIFT"
 	IntervalKindExclusion = "EXCLUSION"
+	// IntervalKindShadow pairs someone with whoever is on call, without paging them.
+	IntervalKindShadow = "SHADOW"
+	// IntervalKindPreference asks for shifts with a positive weight, or to avoid them with a negative weight.
+	IntervalKindPreference = "PREFERENCE"
+)
+
+// Interval sources tell where an interval came from.
+const (
+	// IntervalSourceUnknown is for intervals added before sources were recorded.
+	IntervalSourceUnknown  = ""
+	IntervalSourceManual   = "MANUAL"
+	IntervalSourceGenerate = "GENERATE"
+	IntervalSourceImport   = "IMPORT"
 )
 
 const (
 	EventKindAdd    = "ADD"
 	EventKindRemove = "REMOVE"
+	// EventKindShadow has the person shadow others until the event's time, instead of taking shifts.
+	EventKindShadow = "SHADOW"
 )
 
+// BusyTimeout is how long SQLite waits for another process to let go of the database before it gives up.
+const BusyTimeout = 5 * time.Second
+
+// LayerPrimary is the layer that intervals belong to unless they name another.
+// Every schedule has it, and other layers come after it.
+const LayerPrimary = ""
+
+// Schema is the latest schema, for setting up a fresh database in one go.
+// [Migrate] arrives at the same schema one migration at a time.
+//
 //go:embed schema.sql
 var Schema string
 
+// Open opens the database, and checks that its schema is at the [Latest] version.
+//
+// SQLite files are in WAL mode so that reads don't block the writer, wait up to [BusyTimeout] for a lock,
+// and begin every transaction with BEGIN IMMEDIATE so that two writers can't both read before either writes.
+// Opts override these, like [ReadOnly] does.
 func Open(path string, opts url.Values) (*sql.DB, error) {
+	db, err := OpenUnchecked(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := check(context.Background(), db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// ReadOnly returns the opts to [Open] the database at path for a command that only reads.
+// SQLite transactions then begin deferred, so that readers neither wait for the write lock nor hold it up,
+// and PostgreSQL refuses to write at all.
+func ReadOnly(path string) url.Values {
+	if isPostgres(path) {
+		return url.Values{"default_transaction_read_only": {"on"}}
+	}
+	return url.Values{"_txlock": {"deferred"}}
+}
+
+// OpenUnchecked opens the database whatever version its schema is at, so that [Migrate] can bring it up to date.
+//
+// A path starting with postgres:// or postgresql:// connects to PostgreSQL, and any other path is an SQLite file.
+func OpenUnchecked(path string, opts url.Values) (*sql.DB, error) {
+	if isPostgres(path) {
+		return openPostgres(path, opts)
+	}
 	if opts == nil {
 		opts = make(url.Values)
 	}
 	opts.Set("_fk", "true")
+	for k, v := range map[string]string{
+		"_journal_mode": "WAL",
+		"_busy_timeout": strconv.FormatInt(BusyTimeout.Milliseconds(), 10),
+		"_txlock":       "immediate",
+	} {
+		if !opts.Has(k) {
+			opts.Set(k, v)
+		}
+	}
 	return sql.Open("sqlite3", path+"?"+opts.Encode())
 }
 
 func (i Interval) String() string {
+	var id string
+	if i.ID != 0 {
+		id = fmt.Sprintf(" #%d", i.ID)
+	}
+	var layer string
+	if i.Layer != LayerPrimary {
+		layer = fmt.Sprintf(" layer %q", i.Layer)
+	}
+	if i.Kind == IntervalKindPreference {
+		layer += fmt.Sprintf(" weight %g", i.Weight)
+	}
 	return fmt.Sprintf(
-		"%s for %q in %q [%s, %s)",
+		"%s for %q in %q%s [%s, %s)%s",
 		i.Kind,
 		i.Person,
 		i.Schedule,
+		layer,
 		i.StartAt.Format(time.RFC3339),
 		i.EndBefore.Format(time.RFC3339),
+		id,
 	)
 }
+
+// Params returns the parameters to add a copy of the interval, which gets an ID of its own.
+func (i Interval) Params() AddIntervalParams {
+	return AddIntervalParams{
+		Person:     i.Person,
+		Schedule:   i.Schedule,
+		StartAt:    i.StartAt,
+		EndBefore:  i.EndBefore,
+		Kind:       i.Kind,
+		Generation: i.Generation,
+		Layer:      i.Layer,
+		Weight:     i.Weight,
+		Source:     i.Source,
+		Author:     i.Author,
+		Reason:     i.Reason,
+		CreatedAt:  i.CreatedAt,
+		Priority:   i.Priority,
+	}
+}
+
+// Interval returns the interval that arg adds, before it has an ID.
+func (arg AddIntervalParams) Interval() Interval {
+	return Interval{
+		Person:     arg.Person,
+		Schedule:   arg.Schedule,
+		StartAt:    arg.StartAt,
+		EndBefore:  arg.EndBefore,
+		Kind:       arg.Kind,
+		Generation: arg.Generation,
+		Layer:      arg.Layer,
+		Weight:     arg.Weight,
+		Source:     arg.Source,
+		Author:     arg.Author,
+		Reason:     arg.Reason,
+		CreatedAt:  arg.CreatedAt,
+		Priority:   arg.Priority,
+	}
+}
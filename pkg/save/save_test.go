@@ -3,12 +3,12 @@ package save_test
 import (
 	"context"
 	"database/sql"
-	"net/url"
 	"testing"
 	"time"
 
 	"github.com/jreut/pager/v2/pkg/assert"
 	"github.com/jreut/pager/v2/pkg/save"
+	"github.com/jreut/pager/v2/pkg/save/savetest"
 )
 
 func TestAddInterval(t *testing.T) {
@@ -18,37 +18,47 @@ func TestAddInterval(t *testing.T) {
 	const schedule = "default"
 	assert.Nil(t, q.AddSchedule(ctx, schedule))
 	const alice = "alice"
+	assert.Nil(t, q.AddPerson(ctx, save.AddPersonParams{Name: alice}))
 
 	t0 := time.Date(2022, 10, 31, 13, 25, 42, 12345, time.UTC)
 	t1 := time.Date(2022, 11, 3, 13, 25, 42, 12345, time.UTC)
 
-	assert.Error(t, "CHECK constraint failed.*kind", q.AddInterval(ctx, save.AddIntervalParams{
+	assert.Error(t, "("+savetest.ErrCheck+").*kind", q.AddInterval(ctx, save.AddIntervalParams{
 		Person:    alice,
 		StartAt:   t0,
 		EndBefore: t1,
 	}))
 
-	assert.Error(t, "CHECK constraint failed.*kind", q.AddInterval(ctx, save.AddIntervalParams{
+	assert.Error(t, "("+savetest.ErrCheck+").*kind", q.AddInterval(ctx, save.AddIntervalParams{
 		Person:    alice,
 		StartAt:   t0,
 		EndBefore: t1,
 		Kind:      "unknown",
 	}))
 
-	assert.Error(t, "^FOREIGN KEY constraint failed$", q.AddInterval(ctx, save.AddIntervalParams{
+	assert.Error(t, savetest.ErrForeignKey, q.AddInterval(ctx, save.AddIntervalParams{
 		Person:    "unknown",
 		StartAt:   t0,
 		EndBefore: t1,
 		Kind:      save.IntervalKindShift,
 	}))
 
-	assert.Error(t, "^FOREIGN KEY constraint failed$", q.AddInterval(ctx, save.AddIntervalParams{
+	assert.Error(t, savetest.ErrForeignKey, q.AddInterval(ctx, save.AddIntervalParams{
 		Person:    alice,
 		StartAt:   t0,
 		EndBefore: t1,
 		Kind:      save.IntervalKindShift,
 	}))
 
+	// Only people who were added can take shifts.
+	assert.Error(t, savetest.ErrForeignKey, q.AddInterval(ctx, save.AddIntervalParams{
+		Person:    "unknown",
+		Schedule:  schedule,
+		StartAt:   t0,
+		EndBefore: t1,
+		Kind:      save.IntervalKindShift,
+	}))
+
 	assert.Nil(t, q.AddInterval(ctx, save.AddIntervalParams{
 		Person:    alice,
 		Schedule:  schedule,
@@ -75,7 +85,7 @@ func TestAddInterval(t *testing.T) {
 	}))
 
 	// Backwards doesn't work
-	assert.Error(t, "CHECK constraint failed", q.AddInterval(ctx, save.AddIntervalParams{
+	assert.Error(t, savetest.ErrCheck, q.AddInterval(ctx, save.AddIntervalParams{
 		Person:    alice,
 		Schedule:  schedule,
 		StartAt:   t1,
@@ -86,10 +96,59 @@ func TestAddInterval(t *testing.T) {
 
 func testdb(t *testing.T, ctx context.Context) *sql.DB {
 	t.Helper()
-	db, err := save.Open(":memory:", url.Values{"cache": []string{"shared"}})
-	assert.Nil(t, err)
-	t.Cleanup(func() { db.Close() })
-	_, err = db.ExecContext(ctx, save.Schema)
+	return savetest.DB(t, ctx)
+}
+
+func TestListLayers(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+	for _, s := range []string{"a", "b"} {
+		assert.Nil(t, q.AddSchedule(ctx, s))
+	}
+	// Layers come in the order they were added, whatever their names and schedules.
+	for _, l := range []save.AddLayerParams{
+		{Schedule: "a", Name: "secondary"},
+		{Schedule: "b", Name: "tertiary"},
+		{Schedule: "a", Name: "manager"},
+		{Schedule: "b", Name: "secondary"},
+	} {
+		assert.Nil(t, q.AddLayer(ctx, l))
+	}
+	assert.Error(t, savetest.ErrUnique, q.AddLayer(ctx, save.AddLayerParams{Schedule: "a", Name: "manager"}))
+	for schedule, want := range map[string][]string{
+		"a": {"secondary", "manager"},
+		"b": {"tertiary", "secondary"},
+	} {
+		got, err := q.ListLayers(ctx, schedule)
+		assert.Nil(t, err)
+		assert.Cmp(t, want, got)
+	}
+}
+
+func TestListEvents(t *testing.T) {
+	ctx := context.Background()
+	q := save.New(testdb(t, ctx))
+	assert.Nil(t, q.AddSchedule(ctx, "schedule"))
+	for _, p := range []string{"alice", "bob", "carol"} {
+		assert.Nil(t, q.AddPerson(ctx, save.AddPersonParams{Name: p}))
+	}
+	t0 := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	// Events at the same time come in the order they were added.
+	for _, e := range []save.AddEventParams{
+		{Person: "bob", Kind: save.EventKindAdd, At: t0.AddDate(0, 0, 1)},
+		{Person: "carol", Kind: save.EventKindAdd, At: t0},
+		{Person: "bob", Kind: save.EventKindRemove, At: t0},
+		{Person: "alice", Kind: save.EventKindAdd, At: t0},
+	} {
+		e.Schedule = "schedule"
+		assert.Nil(t, q.AddEvent(ctx, e))
+	}
+	got, err := q.ListEvents(ctx, "schedule")
 	assert.Nil(t, err)
-	return db
+	assert.Cmp(t, []save.Event{
+		{Person: "carol", Schedule: "schedule", Kind: save.EventKindAdd, At: t0},
+		{Person: "bob", Schedule: "schedule", Kind: save.EventKindRemove, At: t0},
+		{Person: "alice", Schedule: "schedule", Kind: save.EventKindAdd, At: t0},
+		{Person: "bob", Schedule: "schedule", Kind: save.EventKindAdd, At: t0.AddDate(0, 0, 1)},
+	}, got)
 }
@@ -0,0 +1,74 @@
+// Package savetest sets up databases for tests.
+//
+// Tests run against SQLite in memory, or against PostgreSQL when $PAGER_TEST_POSTGRES holds a DSN like
+// postgres://localhost/pager_test?sslmode=disable.
+package savetest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jreut/pager/v2/pkg/assert"
+	"github.com/jreut/pager/v2/pkg/save"
+)
+
+// Error patterns that match what either database says when a constraint fails.
+const (
+	ErrCheck      = `CHECK constraint failed|violates check constraint`
+	ErrForeignKey = `FOREIGN KEY constraint failed|violates foreign key constraint`
+	ErrUnique     = `UNIQUE constraint failed|violates unique constraint`
+)
+
+const postgresEnv = "PAGER_TEST_POSTGRES"
+
+var schemas atomic.Int64
+
+// DB returns an empty database at the latest schema, which is gone after the test.
+// On PostgreSQL each test gets a schema of its own.
+func DB(t *testing.T, ctx context.Context) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv(postgresEnv)
+	if dsn == "" {
+		return SQLite(t, ctx)
+	}
+	admin, err := save.OpenUnchecked(dsn, nil)
+	assert.Nil(t, err)
+	schema := fmt.Sprintf("test_%d_%d", os.Getpid(), schemas.Add(1))
+	_, err = admin.ExecContext(ctx, `CREATE SCHEMA `+schema)
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_, err := admin.ExecContext(context.Background(), `DROP SCHEMA `+schema+` CASCADE`)
+		assert.Nil(t, err)
+		admin.Close()
+	})
+	db, err := save.OpenUnchecked(dsn, url.Values{"search_path": []string{schema}})
+	assert.Nil(t, err)
+	t.Cleanup(func() { db.Close() })
+	_, _, err = save.Migrate(ctx, db)
+	assert.Nil(t, err)
+	return db
+}
+
+// SQLite returns an empty SQLite database in memory at the latest schema, whatever database the other tests use.
+func SQLite(t *testing.T, ctx context.Context) *sql.DB {
+	t.Helper()
+	db, err := save.OpenUnchecked(":memory:", url.Values{"cache": []string{"shared"}})
+	assert.Nil(t, err)
+	t.Cleanup(func() { db.Close() })
+	_, err = db.ExecContext(ctx, save.Schema)
+	assert.Nil(t, err)
+	return db
+}
+
+// SQLiteOnly skips a test that relies on SQLite when the other tests use PostgreSQL.
+func SQLiteOnly(t *testing.T) {
+	t.Helper()
+	if os.Getenv(postgresEnv) != "" {
+		t.Skip("relies on SQLite")
+	}
+}
@@ -0,0 +1,52 @@
+package save
+
+import (
+	"context"
+	"fmt"
+)
+
+// ScheduleTables are the tables that belong to a schedule through their schedule column.
+// Tables come before the ones they refer to, so deleting in this order never breaks a foreign key.
+var ScheduleTables = []string{
+	"interval",
+	"generation",
+	"event",
+	"tag",
+	"holiday",
+	"constraints",
+	"layer",
+	"requirement",
+	"settings",
+	"remote_schedule",
+}
+
+// RenameSchedule moves the schedule and everything in it to a new name.
+//
+// The foreign keys don't cascade, so it adds the schedule under the new name,
+// moves each table's rows over to it, and then deletes the old one.
+func (q *Queries) RenameSchedule(ctx context.Context, from, to string) error {
+	if _, err := q.db.ExecContext(ctx, `
+INSERT INTO schedule(name, archived_at)
+SELECT ?, archived_at FROM schedule WHERE name = ?
+`, to, from); err != nil {
+		return err
+	}
+	for _, table := range ScheduleTables {
+		if _, err := q.db.ExecContext(ctx, `UPDATE `+table+` SET schedule = ? WHERE schedule = ?`, to, from); err != nil {
+			return fmt.Errorf("renaming in %s: %w", table, err)
+		}
+	}
+	_, err := q.db.ExecContext(ctx, `DELETE FROM schedule WHERE name = ?`, from)
+	return err
+}
+
+// DeleteSchedule deletes the schedule and everything in it, removed intervals and all.
+func (q *Queries) DeleteSchedule(ctx context.Context, name string) error {
+	for _, table := range ScheduleTables {
+		if _, err := q.db.ExecContext(ctx, `DELETE FROM `+table+` WHERE schedule = ?`, name); err != nil {
+			return fmt.Errorf("deleting from %s: %w", table, err)
+		}
+	}
+	_, err := q.db.ExecContext(ctx, `DELETE FROM schedule WHERE name = ?`, name)
+	return err
+}
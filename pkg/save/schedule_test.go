@@ -0,0 +1,36 @@
+package save_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/jreut/pager/v2/pkg/assert"
+	"github.com/jreut/pager/v2/pkg/save"
+	"github.com/jreut/pager/v2/pkg/save/savetest"
+)
+
+// TestScheduleTables checks that renaming and deleting a schedule reach every table that refers to it.
+func TestScheduleTables(t *testing.T) {
+	ctx := context.Background()
+	db := savetest.SQLite(t, ctx)
+	rows, err := db.QueryContext(ctx, `
+SELECT DISTINCT m.name
+FROM sqlite_master m, pragma_foreign_key_list(m.name) f
+WHERE m.type = 'table' AND f."table" = 'schedule'
+ORDER BY m.name
+`)
+	assert.Nil(t, err)
+	defer rows.Close()
+	var want []string
+	for rows.Next() {
+		var name string
+		assert.Nil(t, rows.Scan(&name))
+		want = append(want, name)
+	}
+	assert.Nil(t, rows.Err())
+
+	got := append([]string(nil), save.ScheduleTables...)
+	sort.Strings(got)
+	assert.Cmp(t, want, got)
+}
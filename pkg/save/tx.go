@@ -2,11 +2,32 @@ package save
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
 )
 
+// ErrRollback tells WithTx to roll back without reporting an error.
+var ErrRollback = errors.New("roll back")
+
+// ErrBusy means another process kept the database locked for longer than WithTx would wait.
+var ErrBusy = errors.New("database is busy")
+
+// busyRetries is how many times WithTx tries to begin a transaction while the database is busy,
+// each time waiting busyBackoff longer before the next, on top of the busy timeout.
+// Tests that pile on more writers than any real use can raise it.
+var busyRetries = 3
+
+const busyBackoff = 100 * time.Millisecond
+
+// WithTx runs f in a transaction, committing if it succeeds and rolling back if it fails.
+//
+// If the database is locked, WithTx tries again to begin the transaction a few times before failing with [ErrBusy].
+// It never retries f, which may have had effects outside the database.
 func WithTx(db *sql.DB, f func(*sql.Tx) error) error {
-	tx, err := db.Begin()
+	tx, err := begin(db)
 	if err != nil {
 		return err
 	}
@@ -14,7 +35,36 @@ func WithTx(db *sql.DB, f func(*sql.Tx) error) error {
 		if err2 := tx.Rollback(); err2 != nil {
 			return fmt.Errorf("%w: rollback error while handling %v", err2, err)
 		}
-		return err
+		if errors.Is(err, ErrRollback) {
+			return nil
+		}
+		return busy(err)
 	}
-	return tx.Commit()
+	return busy(tx.Commit())
+}
+
+func begin(db *sql.DB) (*sql.Tx, error) {
+	for try := 1; ; try++ {
+		tx, err := db.Begin()
+		if err == nil || !isBusy(err) {
+			return tx, err
+		}
+		if try == busyRetries {
+			return nil, fmt.Errorf("%w: gave up after %d tries: another command is still using it, so try again later", ErrBusy, try)
+		}
+		time.Sleep(time.Duration(try) * busyBackoff)
+	}
+}
+
+// busy makes a busy error into an ErrBusy, and leaves any other error alone.
+func busy(err error) error {
+	if isBusy(err) {
+		return fmt.Errorf("%w: %v", ErrBusy, err)
+	}
+	return err
+}
+
+func isBusy(err error) bool {
+	var e sqlite3.Error
+	return errors.As(err, &e) && (e.Code == sqlite3.ErrBusy || e.Code == sqlite3.ErrLocked)
 }
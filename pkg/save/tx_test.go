@@ -4,13 +4,20 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/jreut/pager/v2/pkg/assert"
 	"github.com/jreut/pager/v2/pkg/save"
+	"github.com/jreut/pager/v2/pkg/save/savetest"
 )
 
 func TestTx(t *testing.T) {
+	savetest.SQLiteOnly(t)
 	ctx := context.Background()
 	db := testdb(t, ctx)
 
@@ -66,4 +73,146 @@ func TestTx(t *testing.T) {
 	})
 	assert.Nil(t, err)
 	assertrows(t, []int{1, 4})
+
+	err = save.WithTx(db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `INSERT INTO t(rowid) VALUES (5);`)
+		if err != nil {
+			return err
+		}
+		return save.ErrRollback
+	})
+	assert.Nil(t, err)
+	assertrows(t, []int{1, 4})
+}
+
+// TestTxConcurrent has several processes' worth of connections add intervals and bump a counter at once.
+// None of them should fail to get the lock or lose another's update.
+func TestTxConcurrent(t *testing.T) {
+	const (
+		writers = 8
+		writes  = 25
+	)
+	// Far more writers contend here than ever do in practice, so give them more time to take turns.
+	save.SetBusyRetries(t, 20)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "db.sqlite3")
+	db, err := save.OpenUnchecked(path, nil)
+	assert.Nil(t, err)
+	defer db.Close()
+	_, _, err = save.Migrate(ctx, db)
+	assert.Nil(t, err)
+	_, err = db.ExecContext(ctx, `CREATE TABLE counter(n INTEGER NOT NULL); INSERT INTO counter(n) VALUES (0)`)
+	assert.Nil(t, err)
+	q := save.New(db)
+	assert.Nil(t, q.AddSchedule(ctx, "default"))
+	assert.Nil(t, q.AddPerson(ctx, save.AddPersonParams{Name: "alice"}))
+
+	t0 := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*writes)
+	for w := 0; w < writers; w++ {
+		w := w
+		// Each writer has a handle of its own, like a separate process would.
+		db, err := save.Open(path, nil)
+		assert.Nil(t, err)
+		defer db.Close()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < writes; i++ {
+				errs <- save.WithTx(db, func(tx *sql.Tx) error {
+					var n int
+					if err := tx.QueryRowContext(ctx, `SELECT n FROM counter`).Scan(&n); err != nil {
+						return err
+					}
+					start := t0.Add(time.Duration(w*writes+i) * time.Hour)
+					if err := save.New(tx).AddInterval(ctx, save.AddIntervalParams{
+						Person:    "alice",
+						Schedule:  "default",
+						StartAt:   start,
+						EndBefore: start.Add(time.Hour),
+						Kind:      save.IntervalKindShift,
+					}); err != nil {
+						return err
+					}
+					_, err := tx.ExecContext(ctx, `UPDATE counter SET n = ?`, n+1)
+					return err
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	// Giving up while the database is busy is fine, but every write that succeeds must count.
+	var ok int
+	for err := range errs {
+		if err == nil {
+			ok++
+			continue
+		}
+		if !errors.Is(err, save.ErrBusy) {
+			t.Errorf("want nil or save.ErrBusy, got %v", err)
+		}
+	}
+
+	var n, intervals int
+	assert.Nil(t, db.QueryRowContext(ctx, `SELECT n FROM counter`).Scan(&n))
+	assert.Nil(t, db.QueryRowContext(ctx, `SELECT count(*) FROM interval`).Scan(&intervals))
+	assert.Cmp(t, ok, n)
+	assert.Cmp(t, ok, intervals)
+}
+
+// TestTxBusy holds the lock for longer than another handle waits for it.
+func TestTxBusy(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "db.sqlite3")
+	db, err := save.OpenUnchecked(path, nil)
+	assert.Nil(t, err)
+	defer db.Close()
+	_, _, err = save.Migrate(ctx, db)
+	assert.Nil(t, err)
+
+	impatient, err := save.Open(path, url.Values{"_busy_timeout": []string{"10"}})
+	assert.Nil(t, err)
+	defer impatient.Close()
+
+	err = save.WithTx(db, func(*sql.Tx) error {
+		return save.WithTx(impatient, func(*sql.Tx) error { return nil })
+	})
+	if !errors.Is(err, save.ErrBusy) {
+		t.Fatalf("want save.ErrBusy, got %v", err)
+	}
+	assert.Error(t, fmt.Sprintf("^%s: gave up after 3 tries", save.ErrBusy), err)
+}
+
+// TestTxReadOnly reads while another handle holds the write lock.
+func TestTxReadOnly(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "db.sqlite3")
+	db, err := save.OpenUnchecked(path, nil)
+	assert.Nil(t, err)
+	defer db.Close()
+	_, _, err = save.Migrate(ctx, db)
+	assert.Nil(t, err)
+
+	opts := save.ReadOnly(path)
+	opts.Set("_busy_timeout", "10")
+	reader, err := save.Open(path, opts)
+	assert.Nil(t, err)
+	defer reader.Close()
+
+	assert.Nil(t, save.WithTx(db, func(tx *sql.Tx) error {
+		if err := save.New(tx).AddSchedule(ctx, "default"); err != nil {
+			return err
+		}
+		return save.WithTx(reader, func(tx *sql.Tx) error {
+			schedules, err := save.New(tx).ListSchedules(ctx)
+			if err != nil {
+				return err
+			}
+			// The write isn't committed yet.
+			assert.Cmp(t, 0, len(schedules))
+			return nil
+		})
+	}))
 }